@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// a11yPageChecks are the accessibility checks run on generated pages with
+// -a11yCheck.
+var a11yPageChecks = []pageCheck{
+	{Name: "heading-order", Check: checkHeadingOrder},
+	{Name: "duplicate-id", Check: checkDuplicateIDs},
+	{Name: "link-text", Check: checkLinkText},
+	{Name: "html-lang", Check: checkHTMLLang},
+}
+
+// a11yRules are the lint rules -strictA11y turns into build failures.
+var a11yRules = map[string]bool{
+	"image-alt":     true,
+	"heading-order": true,
+	"duplicate-id":  true,
+	"link-text":     true,
+	"html-lang":     true,
+}
+
+// vagueLinkTexts are link texts that say nothing about the target.
+var vagueLinkTexts = map[string]bool{
+	"here":       true,
+	"click here": true,
+	"link":       true,
+	"링크":         true,
+	"여기":         true,
+	"이곳":         true,
+	"클릭":         true,
+}
+
+// checkHeadingOrder reports headings inside <article> that skip a level on
+// the way down, e.g. an h4 right after an h2.
+func checkHeadingOrder(toks []htmlToken) []string {
+	var msgs []string
+	depth, last := 0, 0
+	for _, t := range toks {
+		switch {
+		case t.Tag == "article" && t.Type == htmlStartTag:
+			if depth == 0 {
+				last = 0
+			}
+			depth++
+		case t.Tag == "article" && t.Type == htmlEndTag && depth > 0:
+			depth--
+		case depth > 0 && t.Type == htmlStartTag && len(t.Tag) == 2 && t.Tag[0] == 'h' && t.Tag[1] >= '1' && t.Tag[1] <= '6':
+			level := int(t.Tag[1] - '0')
+			if last > 0 && level > last+1 {
+				msgs = append(msgs, fmt.Sprintf("line %d: h%d follows h%d", t.Line, level, last))
+			}
+			last = level
+		}
+	}
+	return msgs
+}
+
+// checkDuplicateIDs reports id attributes used more than once.
+func checkDuplicateIDs(toks []htmlToken) []string {
+	var msgs []string
+	seen := make(map[string]int)
+	for _, t := range toks {
+		id, ok := t.Attrs["id"]
+		if t.Type != htmlStartTag || !ok {
+			continue
+		}
+		if first, dup := seen[id]; dup {
+			msgs = append(msgs, fmt.Sprintf("line %d: id %q already used on line %d", t.Line, id, first))
+			continue
+		}
+		seen[id] = t.Line
+	}
+	return msgs
+}
+
+// checkLinkText reports links whose whole text is a vague word like "here".
+func checkLinkText(toks []htmlToken) []string {
+	var msgs []string
+	var text strings.Builder
+	start, in := 0, false
+	for _, t := range toks {
+		switch {
+		case t.Tag == "a" && t.Type == htmlStartTag:
+			text.Reset()
+			start, in = t.Line, true
+		case t.Tag == "a" && t.Type == htmlEndTag && in:
+			s := strings.ToLower(strings.Join(strings.Fields(text.String()), " "))
+			if vagueLinkTexts[s] {
+				msgs = append(msgs, fmt.Sprintf("line %d: link text %q does not describe the target", start, s))
+			}
+			in = false
+		case t.Type == htmlText && in:
+			text.WriteString(t.Text)
+		}
+	}
+	return msgs
+}
+
+// checkHTMLLang reports an <html> element without a lang attribute.
+func checkHTMLLang(toks []htmlToken) []string {
+	for _, t := range toks {
+		if t.Type == htmlStartTag && t.Tag == "html" {
+			if strings.TrimSpace(t.Attrs["lang"]) == "" {
+				return []string{"<html> has no lang attribute"}
+			}
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+const (
+	analyticsPlausible   = "plausible"
+	analyticsGoatCounter = "goatcounter"
+)
+
+// analyticsConfig is the analytics setup exposed as .Site.Analytics. It is
+// nil in templates when no provider is configured.
+type analyticsConfig struct {
+	Provider string
+	// Domain is the Plausible site domain or the GoatCounter site code (or
+	// full count endpoint URL).
+	Domain    string
+	ScriptURL string
+}
+
+var analyticsSnippets = map[string]*template.Template{
+	analyticsPlausible: template.Must(template.New(analyticsPlausible).Parse(
+		`<script defer data-domain="{{ .Domain }}" src="{{ .ScriptURL }}"></script>`)),
+	analyticsGoatCounter: template.Must(template.New(analyticsGoatCounter).Parse(
+		`<script data-goatcounter="{{ .Endpoint }}" async src="{{ .ScriptURL }}"></script>`)),
+}
+
+var analyticsDefaultScripts = map[string]string{
+	analyticsPlausible:   "https://plausible.io/js/script.js",
+	analyticsGoatCounter: "https://gc.zgo.at/count.js",
+}
+
+// analyticsSnippet renders the tracking script for the configured provider.
+func analyticsSnippet(a analyticsConfig) (template.HTML, error) {
+	tpl, ok := analyticsSnippets[a.Provider]
+	if !ok {
+		return "", fmt.Errorf("unknown analytics provider %q", a.Provider)
+	}
+	endpoint := a.Domain
+	if a.Provider == analyticsGoatCounter && !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint + ".goatcounter.com/count"
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, map[string]string{
+		"Domain":    a.Domain,
+		"Endpoint":  endpoint,
+		"ScriptURL": firstNonEmpty(a.ScriptURL, analyticsDefaultScripts[a.Provider]),
+	}); err != nil {
+		return "", fmt.Errorf("render analytics snippet: %w", err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// analyticsEnabled reports whether pages in this build carry the snippet.
+// Preview and draft builds never do, so local testing stays out of the stats.
+func analyticsEnabled(cfg config) bool {
+	return cfg.analytics.Provider != "" && cfg.env == envProduction && !cfg.drafts
+}
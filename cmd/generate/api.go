@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// apiVersion is bumped whenever a field of the api/*.json files changes
+// meaning or is removed. Adding fields does not require a bump.
+const apiVersion = 1
+
+type apiPosts struct {
+	Version int       `json:"version"`
+	Posts   []apiPost `json:"posts"`
+}
+
+type apiPost struct {
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Permalink   string   `json:"permalink"`
+	Date        string   `json:"date,omitempty"`
+	Tags        []string `json:"tags"`
+	Summary     string   `json:"summary"`
+	ReadingTime int      `json:"readingTime"`
+}
+
+type apiTags struct {
+	Version int      `json:"version"`
+	Tags    []apiTag `json:"tags"`
+}
+
+type apiTag struct {
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Count int    `json:"count"`
+}
+
+// apiPage is the per-post document written to <slug>/index.json by
+// -jsonPages for clients that render posts themselves.
+type apiPage struct {
+	Version     int      `json:"version"`
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Date        string   `json:"date,omitempty"`
+	Tags        []string `json:"tags"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Lang        string   `json:"lang"`
+	Thumbnail   string   `json:"thumbnail,omitempty"`
+	ReadingTime int      `json:"readingTime"`
+	Draft       bool     `json:"draft,omitempty"`
+	HTML        string   `json:"html"`
+}
+
+// writePageJSON writes p as <slug>/index.json next to its HTML page.
+func writePageJSON(cfg config, p post) error {
+	page := apiPage{
+		Version:     apiVersion,
+		Slug:        p.Slug,
+		Title:       p.Title,
+		URL:         cfg.baseURL + "/" + p.Slug + "/",
+		Tags:        make([]string, 0, len(p.Tags)),
+		Summary:     firstNonEmpty(p.Summary, p.Description),
+		Description: p.Description,
+		Author:      p.Author,
+		Lang:        firstNonEmpty(p.Lang, cfg.language),
+		Thumbnail:   p.Thumbnail,
+		ReadingTime: p.ReadingTime,
+		Draft:       p.Draft,
+		HTML:        string(p.ContentHTML),
+	}
+	if !p.Date.IsZero() {
+		page.Date = p.Date.Format(time.RFC3339)
+	}
+	for _, t := range p.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			page.Tags = append(page.Tags, t)
+		}
+	}
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug), "index.json")
+	return cfg.out.writeJSON(target, page)
+}
+
+// renderAPI writes api/posts.json and api/tags.json. posts must already be
+// sorted newest first.
+func renderAPI(cfg config, posts []post, tags []tagGroup) error {
+	out := apiPosts{Version: apiVersion, Posts: make([]apiPost, 0, len(posts))}
+	for _, p := range posts {
+		ap := apiPost{
+			Slug:        p.Slug,
+			Title:       p.Title,
+			Permalink:   cfg.baseURL + "/" + p.Slug + "/",
+			Tags:        make([]string, 0, len(p.Tags)),
+			Summary:     firstNonEmpty(p.Summary, p.Description),
+			ReadingTime: p.ReadingTime,
+		}
+		if !p.Date.IsZero() {
+			ap.Date = p.Date.Format(time.RFC3339)
+		}
+		for _, t := range p.Tags {
+			if t = strings.TrimSpace(t); t != "" {
+				ap.Tags = append(ap.Tags, t)
+			}
+		}
+		out.Posts = append(out.Posts, ap)
+	}
+
+	tagsOut := apiTags{Version: apiVersion, Tags: make([]apiTag, 0, len(tags))}
+	for _, t := range tags {
+		tagsOut.Tags = append(tagsOut.Tags, apiTag{Name: t.Name, Slug: t.Slug, Count: len(t.Posts)})
+	}
+
+	dir := filepath.Join(cfg.outputDir, "api")
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	if err := cfg.out.writeJSON(filepath.Join(dir, "posts.json"), out); err != nil {
+		return err
+	}
+	return cfg.out.writeJSON(filepath.Join(dir, "tags.json"), tagsOut)
+}
+
+func (o *buildOutput) writeJSON(target string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", target, err)
+	}
+	if err := o.writeIfChanged(target, append(data, '\n')); err != nil {
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+	return nil
+}
+
+// readingTime estimates minutes to read text: Hangul and Han characters at
+// 500 per minute, everything else at 200 words per minute. Never below one.
+func readingTime(text string) int {
+	var cjk, words int
+	for _, field := range strings.Fields(text) {
+		other := false
+		for _, r := range field {
+			if unicode.In(r, unicode.Hangul, unicode.Han) {
+				cjk++
+			} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				other = true
+			}
+		}
+		if other {
+			words++
+		}
+	}
+	minutes := (float64(cjk)/500 + float64(words)/200)
+	if minutes < 1 {
+		return 1
+	}
+	return int(minutes + 0.5)
+}
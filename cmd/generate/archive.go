@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultYearTemplate is used when templates/year.html does not exist and
+// mirrors the structure of the tag page.
+const defaultYearTemplate = `{{ define "content" }}
+<section class="tag-page year-page">
+  <h2>{{ label "year" .Year.Year }}</h2>
+  <p class="meta">{{ label "postCount" (len .Posts) }}</p>
+  <ul class="tag-posts">
+    {{ range .Posts }}
+    <li>
+      <span class="post-date">{{ formatDate .Date }}</span>
+      <a href="{{ postURL .Slug }}">{{ .Title }}</a>
+    </li>
+    {{ end }}
+  </ul>
+  {{ if .Year.Months }}
+  <ul class="tag-list month-list">
+    {{ range .Year.Months }}
+    <li><a href="{{ relURL .URL }}">{{ .Name }}</a> <span class="count">({{ len .Posts }})</span></li>
+    {{ end }}
+  </ul>
+  {{ end }}
+  <p class="back-link">
+    {{ if .NextYear }}<a href="{{ relURL .NextYear.URL }}">← {{ label "year" .NextYear.Year }}</a>{{ end }}
+    {{ if .PrevYear }}<a href="{{ relURL .PrevYear.URL }}">{{ label "year" .PrevYear.Year }} →</a>{{ end }}
+  </p>
+</section>
+{{ end }}`
+
+// defaultMonthTemplate is used when templates/month.html does not exist.
+const defaultMonthTemplate = `{{ define "content" }}
+<section class="tag-page month-page">
+  <h2>{{ .Month.Title }}</h2>
+  <p class="meta">{{ label "postCount" (len .Posts) }}</p>
+  <ul class="tag-posts">
+    {{ range .Posts }}
+    <li>
+      <span class="post-date">{{ formatDate .Date }}</span>
+      <a href="{{ postURL .Slug }}">{{ .Title }}</a>
+    </li>
+    {{ end }}
+  </ul>
+  <p class="back-link">
+    {{ if .NextMonth }}<a href="{{ relURL .NextMonth.URL }}">← {{ .NextMonth.Title }}</a>{{ end }}
+    <a href="{{ relURL .Year.URL }}">{{ label "yearAll" .Year.Year }}</a>
+    {{ if .PrevMonth }}<a href="{{ relURL .PrevMonth.URL }}">{{ .PrevMonth.Title }} →</a>{{ end }}
+  </p>
+</section>
+{{ end }}`
+
+// defaultArchiveTemplate is used when templates/archive.html does not exist.
+const defaultArchiveTemplate = `{{ define "content" }}
+<section class="archive">
+  <h2>{{ label "archive" }}</h2>
+  {{ range .Years }}
+  <h3><a href="{{ relURL .URL }}">{{ label "year" .Year }}</a></h3>
+  <ul class="tag-posts">
+    {{ range .Posts }}
+    <li>
+      <span class="post-date">{{ formatDate .Date }}</span>
+      <a href="{{ postURL .Slug }}">{{ .Title }}</a>
+    </li>
+    {{ end }}
+  </ul>
+  {{ else }}
+  <p>{{ label "noPosts" }}</p>
+  {{ end }}
+</section>
+{{ end }}`
+
+const archiveURL = "/archive/"
+
+type yearGroup struct {
+	Year   int
+	Slug   string
+	URL    string
+	Posts  []post
+	Months []monthGroup
+}
+
+// monthGroup is one month of a yearGroup. Name is the localized month name
+// and Title the localized "month year" heading.
+type monthGroup struct {
+	Year  int
+	Month time.Month
+	Name  string
+	Title string
+	Slug  string
+	URL   string
+	Posts []post
+}
+
+// archiveSlug returns the zero-padded date path used by archive pages, e.g.
+// "2024" or "2024/05". Every date-based path must be built with it so
+// archives and any date permalinks agree on the layout.
+func archiveSlug(year int, month time.Month) string {
+	if month == 0 {
+		return fmt.Sprintf("%04d", year)
+	}
+	return fmt.Sprintf("%04d/%02d", year, int(month))
+}
+
+// buildYearGroups groups dated posts by year and month, newest first. Posts
+// keep the order they were given in, so callers should pass them sorted.
+func buildYearGroups(posts []post, lang string) []yearGroup {
+	index := make(map[int]int)
+	var groups []yearGroup
+	for _, p := range posts {
+		if p.Date.IsZero() {
+			continue
+		}
+		y := p.Date.Year()
+		i, ok := index[y]
+		if !ok {
+			slug := archiveSlug(y, 0)
+			groups = append(groups, yearGroup{Year: y, Slug: slug, URL: "/" + slug + "/"})
+			i = len(groups) - 1
+			index[y] = i
+		}
+		g := &groups[i]
+		g.Posts = append(g.Posts, p)
+
+		m := p.Date.Month()
+		mi := -1
+		for k := range g.Months {
+			if g.Months[k].Month == m {
+				mi = k
+				break
+			}
+		}
+		if mi == -1 {
+			g.Months = append(g.Months, newMonthGroup(y, m, lang))
+			mi = len(g.Months) - 1
+		}
+		g.Months[mi].Posts = append(g.Months[mi].Posts, p)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Year > groups[j].Year
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.Months, func(i, j int) bool {
+			return g.Months[i].Month > g.Months[j].Month
+		})
+	}
+	return groups
+}
+
+func newMonthGroup(year int, month time.Month, lang string) monthGroup {
+	slug := archiveSlug(year, month)
+	return monthGroup{
+		Year:  year,
+		Month: month,
+		Name:  monthName(lang, month),
+		Title: monthTitle(lang, year, month),
+		Slug:  slug,
+		URL:   "/" + slug + "/",
+	}
+}
+
+// allMonths flattens the month groups of years, newest first.
+func allMonths(years []yearGroup) []monthGroup {
+	var months []monthGroup
+	for _, y := range years {
+		months = append(months, y.Months...)
+	}
+	return months
+}
+
+// baseLang reduces a language tag such as "ko-KR" to its primary subtag.
+func baseLang(lang string) string {
+	lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	return lang
+}
+
+var koreanWeekdays = [...]string{"일", "월", "화", "수", "목", "금", "토"}
+
+// localizedDate formats the calendar date of t for readers of lang, e.g.
+// "2024년 5월 3일 (금)" or "Friday, May 3, 2024"; other languages use ISO.
+// Feeds and other machine formats never go through it.
+func localizedDate(lang string, t time.Time) string {
+	switch baseLang(lang) {
+	case "ko":
+		return fmt.Sprintf("%d년 %d월 %d일 (%s)", t.Year(), int(t.Month()), t.Day(), koreanWeekdays[t.Weekday()])
+	case "en":
+		return t.Format("Monday, January 2, 2006")
+	default:
+		return formatDate(t, nil)
+	}
+}
+
+func monthName(lang string, month time.Month) string {
+	switch baseLang(lang) {
+	case "ko":
+		return fmt.Sprintf("%d월", int(month))
+	default:
+		return month.String()
+	}
+}
+
+func monthTitle(lang string, year int, month time.Month) string {
+	switch baseLang(lang) {
+	case "ko":
+		return fmt.Sprintf("%d년 %d월", year, int(month))
+	default:
+		return fmt.Sprintf("%s %d", month, year)
+	}
+}
+
+// reservedSlugs lists the top-level paths the generator writes itself so
+// checkSlugs can reject posts that would overwrite them.
+func reservedSlugs(years []yearGroup) map[string]string {
+	reserved := map[string]string{
+		"api":     "the JSON API",
+		"archive": "the archive page",
+		"assets":  "the asset directory",
+		"authors": "the author pages",
+		"feeds":   "the feed directory",
+		"page":    "the homepage pages",
+		"search":  "the search page",
+		"series":  "the series index",
+		"tags":    "the tag pages",
+	}
+	for _, y := range years {
+		reserved[y.Slug] = fmt.Sprintf("the %d archive page", y.Year)
+		for _, m := range y.Months {
+			reserved[m.Slug] = fmt.Sprintf("the %s archive page", m.Slug)
+		}
+	}
+	return reserved
+}
+
+// renderYearPages writes /<year>/ for every year that has posts. PrevYear and
+// NextYear point at the adjacent older and newer years, when they exist.
+func renderYearPages(cfg config, tpl *template.Template, years []yearGroup) error {
+	for i, y := range years {
+		dir := filepath.Join(cfg.outputDir, y.Slug)
+		if err := ensureDir(dir); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, "index.html")
+		data := map[string]any{
+			"Title": uiLabel(cfg.language, "yearTitle", y.Year),
+			"Year":  y,
+			"Posts": y.Posts,
+			"Site":  newSite(cfg),
+		}
+		if i > 0 {
+			data["NextYear"] = years[i-1]
+		}
+		if i+1 < len(years) {
+			data["PrevYear"] = years[i+1]
+		}
+		if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+			return &RenderError{Kind: "year", Slug: strconv.Itoa(y.Year), Target: target, Err: err}
+		}
+	}
+	return nil
+}
+
+// renderMonthPages writes /<year>/<month>/ for every month that has posts.
+// PrevMonth and NextMonth skip months without posts.
+func renderMonthPages(cfg config, tpl *template.Template, years []yearGroup) error {
+	yearOf := make(map[int]yearGroup, len(years))
+	for _, y := range years {
+		yearOf[y.Year] = y
+	}
+	months := allMonths(years)
+	for i, m := range months {
+		dir := filepath.Join(cfg.outputDir, filepath.FromSlash(m.Slug))
+		if err := ensureDir(dir); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, "index.html")
+		data := map[string]any{
+			"Title": m.Title,
+			"Month": m,
+			"Year":  yearOf[m.Year],
+			"Posts": m.Posts,
+			"Site":  newSite(cfg),
+		}
+		if i > 0 {
+			data["NextMonth"] = months[i-1]
+		}
+		if i+1 < len(months) {
+			data["PrevMonth"] = months[i+1]
+		}
+		if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+			return &RenderError{Kind: "month", Slug: m.Slug, Target: target, Err: err}
+		}
+	}
+	return nil
+}
+
+// renderArchive writes the single /archive/ page listing every listed post
+// under its year. Grouping happens here so the template only ranges.
+func renderArchive(cfg config, tpl *template.Template, years []yearGroup) error {
+	dir := filepath.Join(cfg.outputDir, "archive")
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, "index.html")
+	data := map[string]any{
+		"Title": uiLabel(cfg.language, "archive"),
+		"Years": years,
+		"Site":  newSite(cfg),
+	}
+	if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+		return &RenderError{Kind: "archive", Target: target, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalizedDate(t *testing.T) {
+	ts := time.Date(2025, 11, 4, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"ko", "2025년 11월 4일 (화)"},
+		{"ko-KR", "2025년 11월 4일 (화)"},
+		{"en", "Tuesday, November 4, 2025"},
+		{"en-GB", "Tuesday, November 4, 2025"},
+		{"de", "2025-11-04"},
+	}
+	for _, tt := range tests {
+		if got := localizedDate(tt.lang, ts); got != tt.want {
+			t.Errorf("localizedDate(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var cssURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")?#]+)`)
+
+// embeddedRefPattern finds URL candidates in script and style text and in
+// style attributes: quoted strings and url(...) values.
+var embeddedRefPattern = regexp.MustCompile(`["'(]\s*([^"'()\s]+)`)
+
+// assetRefs returns the /assets/ paths that toks, the tokens of one built
+// page, reference through attributes (srcset candidates and url() in style
+// included) or inside script and style elements.
+func assetRefs(cfg config, toks []htmlToken) []string {
+	var refs []string
+	add := func(ref string) {
+		if rel, ok := localAssetPath(cfg, ref); ok {
+			refs = append(refs, rel)
+		}
+	}
+	for _, t := range toks {
+		switch t.Type {
+		case htmlStartTag:
+			for name, v := range t.Attrs {
+				switch name {
+				case "srcset":
+					for _, candidate := range strings.Split(v, ",") {
+						if fields := strings.Fields(candidate); len(fields) > 0 {
+							add(fields[0])
+						}
+					}
+				case "style":
+					for _, m := range embeddedRefPattern.FindAllStringSubmatch(v, -1) {
+						add(m[1])
+					}
+				default:
+					add(v)
+				}
+			}
+		case htmlRawText:
+			for _, m := range embeddedRefPattern.FindAllStringSubmatch(t.Text, -1) {
+				add(m[1])
+			}
+		}
+	}
+	return refs
+}
+
+// localAssetPath returns the path under the asset root that ref points at
+// when it resolves to this site: a root-relative /assets/ path, with or
+// without the -baseURL path, or an absolute URL under -baseURL or -imageCDN.
+func localAssetPath(cfg config, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	for _, base := range []string{cfg.baseURL, cfg.imageCDN, sitePath(cfg)} {
+		if base != "" && strings.HasPrefix(ref, base+"/") {
+			ref = strings.TrimPrefix(ref, base)
+			break
+		}
+	}
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	rel, ok := strings.CutPrefix(ref, "/assets/")
+	if !ok {
+		return "", false
+	}
+	rel = path.Clean(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// copyReferencedAssets copies only the assets in refs, which scanPages
+// found on the built pages, the files the stylesheets among them pull in,
+// and anything matching one of the always patterns. Files are copied in
+// sorted order, followed by the files stylesheets pull in.
+func copyReferencedAssets(cfg config, refs map[string]struct{}) (copyStats, error) {
+	var stats copyStats
+	if _, err := os.Stat(cfg.assetDir); errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+
+	always, err := matchAssets(cfg.assetDir, cfg.alwaysAssets)
+	if err != nil {
+		return stats, err
+	}
+	for _, rel := range always {
+		refs[rel] = struct{}{}
+	}
+
+	queue := make([]string, 0, len(refs))
+	for rel := range refs {
+		queue = append(queue, rel)
+	}
+	sort.Strings(queue)
+	copied := make(map[string]struct{}, len(refs))
+	for len(queue) > 0 {
+		rel := queue[0]
+		queue = queue[1:]
+		if _, ok := copied[rel]; ok {
+			continue
+		}
+		copied[rel] = struct{}{}
+
+		src := filepath.Join(cfg.assetDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+			cfg.logger.Warn(fmt.Sprintf("참조된 에셋 /assets/%s 파일이 없습니다.", rel))
+			continue
+		}
+		dst := filepath.Join(cfg.outputDir, "assets", filepath.FromSlash(rel))
+		if err := ensureDir(filepath.Dir(dst)); err != nil {
+			return stats, err
+		}
+		n, err := cfg.out.copyFile(src, dst)
+		if err != nil {
+			return stats, err
+		}
+		stats.add(n)
+
+		if strings.EqualFold(path.Ext(rel), ".css") {
+			nested, err := cssAssetRefs(src, rel)
+			if err != nil {
+				return stats, err
+			}
+			queue = append(queue, nested...)
+		}
+	}
+	return stats, nil
+}
+
+// cssAssetRefs returns the url(...) references of a stylesheet resolved
+// against the asset root. Remote and data URLs are ignored.
+func cssAssetRefs(src, rel string) ([]string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return nil, fmt.Errorf("scan assets %s: %w", src, err)
+	}
+	var refs []string
+	for _, m := range cssURLPattern.FindAllSubmatch(data, -1) {
+		ref := strings.TrimSpace(string(m[1]))
+		switch {
+		case ref == "", strings.Contains(ref, ":"), strings.HasPrefix(ref, "//"):
+			continue
+		case strings.HasPrefix(ref, "/assets/"):
+			refs = append(refs, path.Clean(strings.TrimPrefix(ref, "/assets/")))
+		case strings.HasPrefix(ref, "/"):
+			continue
+		default:
+			refs = append(refs, path.Join(path.Dir(rel), ref))
+		}
+	}
+	return refs, nil
+}
+
+// matchAssets returns every file under dir whose slash-separated relative
+// path matches one of the glob patterns. A pattern naming a directory
+// matches everything below it.
+func matchAssets(dir string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	var matched []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, rel)
+			if err != nil {
+				return fmt.Errorf("asset pattern %q: %w", pattern, err)
+			}
+			if ok || strings.HasPrefix(rel, strings.TrimSuffix(pattern, "/")+"/") {
+				matched = append(matched, rel)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(matched)
+	return matched, err
+}
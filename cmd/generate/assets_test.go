@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReferencedAssetsOnly(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.referencedAssetsOnly = true
+	cfg.imageCDN = "https://cdn.example.net"
+	for _, name := range []string{"used.png", "cdn.png", "other.png", "stale.png"} {
+		target := filepath.Join(cfg.assetDir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\n"+
+		"![cdn](/assets/cdn.png)\n\n"+
+		"<a href=\"/assets/used.png\">used</a> and <a href=\"https://other.dev/assets/other.png\">theirs</a>\n")
+	// A page left by an earlier build.
+	stale := filepath.Join(cfg.outputDir, "old", "index.html")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte(`<img src="/assets/stale.png" alt="">`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildSite(t, cfg)
+	for name, want := range map[string]bool{"used.png": true, "cdn.png": true, "other.png": false, "stale.png": false} {
+		_, err := os.Stat(filepath.Join(cfg.outputDir, "assets", name))
+		if got := !errors.Is(err, fs.ErrNotExist); got != want {
+			t.Errorf("%s copied = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestReferencedAssetsUnderBaseURLPath(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.baseURL = "https://x.dev/blog"
+	cfg.referencedAssetsOnly = true
+	if err := os.MkdirAll(cfg.assetDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.assetDir, "style.css"), []byte("body{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, cfg)
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "assets", "style.css")); err != nil {
+		t.Errorf("style.css linked as /blog/assets/style.css was not copied: %v", err)
+	}
+}
+
+func TestAssetRefs(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.baseURL = "https://x.dev/blog"
+	cfg.imageCDN = "https://cdn.example.net"
+	page := `<link rel="stylesheet" href="/blog/assets/style.css">
+<img srcset="/assets/small.png 1x, https://cdn.example.net/assets/big.png 2x" src="https://x.dev/blog/assets/a.png?v=2">
+<div style="background: url('/assets/bg.png')"></div>
+<a href="https://other.dev/assets/theirs.png">theirs</a> <a href="/assets/../secret.txt">up</a>
+<script>load("/assets/search.js");</script>
+<p>/assets/text.png</p>`
+	got := assetRefs(cfg, tokenizeHTML([]byte(page)))
+	slices.Sort(got)
+	want := []string{"a.png", "bg.png", "big.png", "search.js", "small.png", "style.css"}
+	if !slices.Equal(got, want) {
+		t.Errorf("assetRefs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"gopkg.in/yaml.v3"
+)
+
+// authorProfile is one entry of the authors data file. Posts refer to it by
+// key in their `author:` front matter.
+type authorProfile struct {
+	Key    string        `yaml:"-"`
+	Name   string        `yaml:"name"`
+	BioMD  string        `yaml:"bio"`
+	Avatar string        `yaml:"avatar"`
+	Links  []authorLink  `yaml:"links"`
+	Bio    template.HTML `yaml:"-"`
+	URL    string        `yaml:"-"`
+}
+
+type authorLink struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+var authorKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// defaultAuthorTemplate is used when templates/author.html does not exist
+// and mirrors the structure of the tag page.
+const defaultAuthorTemplate = `{{ define "content" }}
+<section class="tag-page author-page">
+  {{ with .Author.Avatar }}<img class="author-avatar" src="{{ relURL . }}" alt="" width="96" height="96">{{ end }}
+  <h2>{{ .Author.Name }}</h2>
+  {{ with .Author.Bio }}<div class="author-bio">{{ . }}</div>{{ end }}
+  {{ with .Author.Links }}<p class="author-links">{{ range $i, $l := . }}{{ if $i }} · {{ end }}<a href="{{ $l.URL }}" rel="me noopener">{{ $l.Name }}</a>{{ end }}</p>{{ end }}
+  <p class="meta">{{ label "postCount" (len .Posts) }}</p>
+  <ul class="tag-posts">
+    {{ range .Posts }}
+    <li>
+      <span class="post-date">{{ formatDate .Date }}</span>
+      <a href="{{ postURL .Slug }}">{{ .Title }}</a>
+    </li>
+    {{ end }}
+  </ul>
+</section>
+{{ end }}`
+
+// loadAuthors reads the authors data file, keyed by author key, and renders
+// each bio from markdown. A missing file returns nil, which keeps the plain
+// string `author:` behaviour.
+func loadAuthors(file string) (map[string]*authorProfile, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read authors %s: %w", file, err)
+	}
+	var authors map[string]*authorProfile
+	if err := yaml.Unmarshal(data, &authors); err != nil {
+		return nil, fmt.Errorf("parse authors %s: %w", file, err)
+	}
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	for key, a := range authors {
+		if !authorKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("authors %s: key %q must be lowercase letters, digits and dashes", file, key)
+		}
+		if a == nil || a.Name == "" {
+			return nil, fmt.Errorf("authors %s: %s needs a name", file, key)
+		}
+		a.Key = key
+		a.URL = "/authors/" + key + "/"
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(a.BioMD), &buf); err != nil {
+			return nil, fmt.Errorf("authors %s: bio of %s: %w", file, key, err)
+		}
+		a.Bio = template.HTML(buf.String())
+	}
+	return authors, nil
+}
+
+// renderAuthorPages writes /authors/<key>/ for every author with listed
+// posts. It only runs when an authors data file is in use.
+func renderAuthorPages(cfg config, tpl *template.Template, groups []tagGroup) error {
+	for _, g := range groups {
+		profile := cfg.authors[g.Slug]
+		if profile == nil {
+			continue
+		}
+		dir := filepath.Join(cfg.outputDir, "authors", g.Slug)
+		if err := ensureDir(dir); err != nil {
+			return err
+		}
+		posts := append([]post(nil), g.Posts...)
+		sort.SliceStable(posts, func(i, j int) bool { return posts[i].Date.After(posts[j].Date) })
+		target := filepath.Join(dir, "index.html")
+		data := map[string]any{
+			"Title":  profile.Name,
+			"Author": profile,
+			"Posts":  posts,
+			"Site":   newSite(cfg),
+		}
+		if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+			return &RenderError{Kind: "author", Slug: g.Slug, Target: target, Err: err}
+		}
+	}
+	return nil
+}
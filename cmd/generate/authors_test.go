@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeAuthors writes an authors data file and loads it into cfg.
+func writeAuthors(t *testing.T, cfg *config, data string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "authors.yaml")
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	authors, err := loadAuthors(file)
+	if err != nil {
+		t.Fatalf("loadAuthors: %v", err)
+	}
+	cfg.authors = authors
+}
+
+func TestLoadAuthorsErrors(t *testing.T) {
+	tests := map[string]string{
+		"Kim:\n  name: Kim\n": `key "Kim" must be lowercase`,
+		"kim:\n  bio: hi\n":   "kim needs a name",
+		"kim: [\n":            "parse authors",
+	}
+	for data, want := range tests {
+		file := filepath.Join(t.TempDir(), "authors.yaml")
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadAuthors(file)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", data, err, want)
+		}
+	}
+	authors, err := loadAuthors(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || authors != nil {
+		t.Errorf("missing file = %v, %v, want nil, nil", authors, err)
+	}
+}
+
+func TestAuthorPages(t *testing.T) {
+	cfg := testConfig(t)
+	writeAuthors(t, &cfg, "kim:\n  name: Kim Minji\n  bio: Writes **Go**.\n  links:\n    - name: GitHub\n      url: https://github.com/kim\n")
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\nauthor: kim\n---\nbody\n")
+	writeContent(t, cfg, "second.md", "---\ntitle: Second\ndate: 2024-05-04\nauthor: kim\n---\nbody\n")
+	writeContent(t, cfg, "anon.md", "---\ntitle: Anonymous\ndate: 2024-05-05\n---\nbody\n")
+	buildSite(t, cfg)
+
+	page := readOutput(t, cfg, "authors/kim/index.html")
+	for _, want := range []string{
+		"<h2>Kim Minji</h2>",
+		"Writes <strong>Go</strong>.",
+		`<a href="https://github.com/kim" rel="me noopener">GitHub</a>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("author page does not hold %s:\n%s", want, page)
+		}
+	}
+	second, hello := strings.Index(page, `href="/second/"`), strings.Index(page, `href="/hello/"`)
+	if second < 0 || hello < 0 || second > hello {
+		t.Errorf("author page does not list both posts newest first:\n%s", page)
+	}
+	if strings.Contains(page, `href="/anon/"`) {
+		t.Error("author page lists a post without the author")
+	}
+	if post := readOutput(t, cfg, "hello/index.html"); !strings.Contains(post, `<a class="author" href="/authors/kim/">Kim Minji</a>`) {
+		t.Errorf("post does not link its author:\n%s", post)
+	}
+}
+
+func TestUnknownAuthor(t *testing.T) {
+	cfg := testConfig(t)
+	writeAuthors(t, &cfg, "kim:\n  name: Kim Minji\n")
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\nauthor: lee\n---\nbody\n")
+	err := run(context.Background(), cfg)
+	var fmErr *FrontMatterError
+	if !errors.As(err, &fmErr) || !strings.Contains(err.Error(), `unknown author "lee"`) {
+		t.Errorf("err = %v, want an unknown author FrontMatterError", err)
+	}
+}
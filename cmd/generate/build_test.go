@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testConfig returns a build config like main's defaults, reading the
+// repository templates and writing to a temporary directory. Posts are
+// written with writeContent.
+func testConfig(t *testing.T) config {
+	t.Helper()
+	dir := t.TempDir()
+	return config{
+		logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+		contentDirs:         []string{filepath.Join(dir, "content")},
+		extensions:          []string{".md"},
+		outputDir:           filepath.Join(dir, "public"),
+		fileMode:            0o644,
+		out:                 newBuildOutput(0o644),
+		templateDir:         filepath.Join("..", "..", "templates"),
+		assetDir:            filepath.Join(dir, "assets"),
+		baseURL:             defaultBaseURL,
+		language:            "ko",
+		location:            time.UTC,
+		now:                 time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		env:                 envProduction,
+		fenceRenderers:      fenceRenderers{},
+		searchIndexLimit:    5000,
+		draftPrefix:         "_",
+		pageSize:            10,
+		tagPageSize:         -1,
+		redirectsFile:       filepath.Join(dir, "redirects.yaml"),
+		headersFile:         filepath.Join(dir, "headers.yaml"),
+		feedDescriptionMode: feedDescriptionPlain,
+		cname:               "off",
+		title:               defaultTitle,
+		description:         defaultDescription,
+		feedUpdateThreshold: 24 * time.Hour,
+		feedExcerptLength:   200,
+	}
+}
+
+// writeContent writes a markdown file under the first content directory.
+func writeContent(t *testing.T, cfg config, name, src string) {
+	t.Helper()
+	target := filepath.Join(cfg.contentDirs[0], filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// buildSite runs a full build of cfg and fails the test on error.
+func buildSite(t *testing.T, cfg config) {
+	t.Helper()
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+// copyTemplates copies the repository templates into a temporary directory
+// and points cfg at it, so a test can replace single files.
+func copyTemplates(t *testing.T, cfg *config) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(cfg.templateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(cfg.templateDir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg.templateDir = dir
+}
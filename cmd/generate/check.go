@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// check validates templates and content the same way run does, but never
+// touches the output directory. Every problem found is returned instead of
+// stopping at the first one so a pre-commit hook can report them together.
+func check(ctx context.Context, cfg config) []error {
+	tpls, err := loadTemplates(cfg)
+	if err != nil {
+		return []error{err}
+	}
+
+	var (
+		problems []error
+		posts    []post
+	)
+	md := newMarkdown(cfg)
+	walkErr := walkContent(ctx, cfg, func(root, path string) error {
+		p, ok, err := loadPost(ctx, md, cfg, root, path)
+		if err != nil {
+			problems = append(problems, err)
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+		if err := tpls.post.ExecuteTemplate(io.Discard, "base", postData(cfg, p)); err != nil {
+			problems = append(problems, &RenderError{Kind: "post", Slug: p.Slug, Target: path, Err: err})
+		}
+		posts = append(posts, p)
+		return nil
+	})
+	if walkErr != nil {
+		problems = append(problems, walkErr)
+	}
+
+	problems = append(problems, checkSlugs(posts, reservedSlugs(buildYearGroups(listedPosts(posts), cfg.language)))...)
+	problems = append(problems, reportTitles(cfg, posts)...)
+
+	findings := lintPosts(cfg, posts)
+	if !cfg.lintFail {
+		reportLint(cfg.logger, findings)
+	}
+	for _, f := range lintFailures(cfg, findings) {
+		problems = append(problems, fmt.Errorf("%s: [%s] %s", f.Path, f.Rule, f.Message))
+	}
+	return problems
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// cspSources holds user-supplied additions to the generated policy, keyed by
+// directive.
+type cspSources map[string][]string
+
+func (c cspSources) String() string {
+	var parts []string
+	for directive, sources := range c {
+		parts = append(parts, directive+" "+strings.Join(sources, " "))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
+
+// Set parses a single "directive source..." entry so the flag can be
+// repeated, e.g. -cspExtra "img-src https://i.imgur.com".
+func (c cspSources) Set(v string) error {
+	fields := strings.Fields(v)
+	if len(fields) < 2 || !strings.HasSuffix(fields[0], "-src") && fields[0] != "base-uri" && fields[0] != "form-action" {
+		return fmt.Errorf("want \"directive source...\", got %q", v)
+	}
+	c[fields[0]] = append(c[fields[0]], fields[1:]...)
+	return nil
+}
+
+// cspDirectiveOrder keeps the emitted policy stable and readable; additional
+// directives from -cspExtra follow in name order.
+var cspDirectiveOrder = []string{"default-src", "script-src", "style-src", "img-src", "font-src", "connect-src", "frame-src", "object-src", "base-uri"}
+
+var scriptBodyPattern = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
+// buildCSP derives a Content-Security-Policy from what the build emits: the
+// hosts of the enabled analytics and comment widgets, remote images found in
+// posts, the image CDN, and hashes of the inline scripts the generator
+// injects itself.
+func buildCSP(cfg config, posts []post) string {
+	policy := map[string][]string{
+		"default-src": {"'self'"},
+		"script-src":  {"'self'"},
+		"style-src":   {"'self'"},
+		"img-src":     {"'self'", "data:"},
+		"connect-src": {"'self'"},
+		"object-src":  {"'none'"},
+		"base-uri":    {"'self'"},
+	}
+	add := func(directive string, sources ...string) {
+		for _, s := range sources {
+			if s != "" {
+				policy[directive] = append(policy[directive], s)
+			}
+		}
+	}
+
+	add("img-src", originOf(cfg.baseURL), originOf(cfg.imageCDN))
+	for _, p := range posts {
+		add("img-src", originOf(p.Thumbnail))
+		for _, src := range imageSources(string(p.ContentHTML)) {
+			add("img-src", originOf(src))
+		}
+	}
+
+	if analyticsEnabled(cfg) {
+		script := firstNonEmpty(cfg.analytics.ScriptURL, analyticsDefaultScripts[cfg.analytics.Provider])
+		add("script-src", originOf(script))
+		switch cfg.analytics.Provider {
+		case analyticsPlausible:
+			add("connect-src", originOf(script))
+		case analyticsGoatCounter:
+			endpoint := cfg.analytics.Domain
+			if !strings.Contains(endpoint, "://") {
+				endpoint = "https://" + endpoint + ".goatcounter.com"
+			}
+			add("connect-src", originOf(endpoint))
+			add("img-src", originOf(endpoint))
+		}
+	}
+
+	// Both comment widgets load their UI in an iframe and inject a <style>
+	// element into the page to size it.
+	switch cfg.comments.Provider {
+	case commentsUtterances:
+		add("script-src", "https://utteranc.es")
+		add("frame-src", "https://utteranc.es")
+		add("style-src", "'unsafe-inline'")
+	case commentsGiscus:
+		add("script-src", "https://giscus.app")
+		add("frame-src", "https://giscus.app")
+		add("style-src", "'unsafe-inline'")
+	}
+
+	for _, m := range scriptBodyPattern.FindAllStringSubmatch(string(serviceWorkerScript(cfg)), -1) {
+		sum := sha256.Sum256([]byte(m[1]))
+		add("script-src", "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+	}
+
+	for directive, sources := range cfg.cspExtra {
+		add(directive, sources...)
+	}
+
+	directives := append([]string(nil), cspDirectiveOrder...)
+	var extra []string
+	for directive := range policy {
+		if !slices.Contains(cspDirectiveOrder, directive) {
+			extra = append(extra, directive)
+		}
+	}
+	sort.Strings(extra)
+	directives = append(directives, extra...)
+
+	var parts []string
+	for _, directive := range directives {
+		sources := policy[directive]
+		if len(sources) == 0 {
+			continue
+		}
+		parts = append(parts, directive+" "+strings.Join(uniqueStrings(sources), " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// originOf returns the scheme and host of an absolute http(s) URL, or "" for
+// anything else.
+func originOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// uniqueStrings drops repeated values, keeping the first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadDataDir reads every .yaml, .yml and .json file under dir into a map
+// exposed as .Site.Data, nested by directory and keyed by file stem:
+// data/projects.yaml is .Site.Data.projects and data/talks/2024.json is
+// .Site.Data.talks.2024 (index .Site.Data.talks "2024" in templates). A
+// missing directory yields nil.
+func loadDataDir(dir string) (map[string]any, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	root := make(map[string]any)
+	sources := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		keys := strings.Split(strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel)), "/")
+		key := strings.Join(keys, "/")
+		if prev, ok := sources[key]; ok {
+			return fmt.Errorf("data %s and %s both define %q", prev, p, key)
+		}
+		sources[key] = p
+
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read data %s: %w", p, err)
+		}
+		var value any
+		if ext == ".json" {
+			err = json.Unmarshal(raw, &value)
+		} else {
+			err = yaml.Unmarshal(raw, &value)
+		}
+		if err != nil {
+			return fmt.Errorf("parse data %s: %w", p, err)
+		}
+
+		m := root
+		for i, k := range keys[:len(keys)-1] {
+			next, ok := m[k]
+			if !ok {
+				child := make(map[string]any)
+				m[k] = child
+				m = child
+				continue
+			}
+			child, ok := next.(map[string]any)
+			if !ok || sources[strings.Join(keys[:i+1], "/")] != "" {
+				return fmt.Errorf("data %s: %q is both a file and a directory", p, strings.Join(keys[:i+1], "/"))
+			}
+			m = child
+		}
+		last := keys[len(keys)-1]
+		if _, ok := m[last]; ok {
+			return fmt.Errorf("data %s: %q is both a file and a directory", p, key)
+		}
+		m[last] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
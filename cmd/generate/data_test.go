@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeDataFiles writes files, keyed by slash-separated path, under a new
+// data directory and returns it.
+func writeDataFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "data")
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDataDir(t *testing.T) {
+	dir := writeDataFiles(t, map[string]string{
+		"projects.yaml":   "- name: pebble\n  stars: 3\n",
+		"talks/2024.json": `{"title": "Go at scale"}`,
+		"talks/notes.txt": "ignored",
+	})
+	got, err := loadDataDir(dir)
+	if err != nil {
+		t.Fatalf("loadDataDir: %v", err)
+	}
+	want := map[string]any{
+		"projects": []any{map[string]any{"name": "pebble", "stars": 3}},
+		"talks":    map[string]any{"2024": map[string]any{"title": "Go at scale"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadDataDir = %#v, want %#v", got, want)
+	}
+
+	if got, err := loadDataDir(filepath.Join(t.TempDir(), "missing")); got != nil || err != nil {
+		t.Errorf("missing directory = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestLoadDataDirErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "same stem",
+			files: map[string]string{"site.yaml": "a: 1\n", "site.json": `{"a": 1}`},
+			want:  `both define "site"`,
+		},
+		{
+			name:  "file and directory",
+			files: map[string]string{"talks.yaml": "a: 1\n", "talks/2024.yaml": "b: 2\n"},
+			want:  `"talks" is both a file and a directory`,
+		},
+		{
+			name:  "invalid yaml",
+			files: map[string]string{"site.yaml": "a: [\n"},
+			want:  "parse data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadDataDir(writeDataFiles(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteDataInTemplates(t *testing.T) {
+	cfg := testConfig(t)
+	copyTemplates(t, &cfg)
+	tpl := `{{ define "content" }}{{ range .Site.Data.projects }}<p>{{ .name }}</p>{{ end }}<p>{{ (index .Site.Data.talks "2024").title }}</p>{{ end }}`
+	if err := os.WriteFile(filepath.Join(cfg.templateDir, "post.html"), []byte(tpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := loadDataDir(writeDataFiles(t, map[string]string{
+		"projects.yaml":   "- name: pebble\n- name: gravel\n",
+		"talks/2024.json": `{"title": "Go at scale"}`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.data = data
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, cfg)
+
+	if page := readOutput(t, cfg, "hello/index.html"); !strings.Contains(page, "<p>pebble</p><p>gravel</p><p>Go at scale</p>") {
+		t.Errorf("post does not render .Site.Data:\n%s", page)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	deployTimeout  = 60 * time.Second
+	cloudFrontHost = "https://cloudfront.amazonaws.com"
+	cloudFrontAPI  = "2020-05-31"
+	cloudFrontZone = "us-east-1"
+	// md5MetaHeader stores an object's MD5 as user metadata. The ETag is only
+	// the MD5 for single-part uploads without SSE-KMS.
+	md5MetaHeader = "X-Amz-Meta-Md5"
+)
+
+// buildManifestName is the file at the output root listing the files the
+// last build wrote, one path relative to the output directory per entry.
+const buildManifestName = ".build-manifest.json"
+
+type buildManifest struct {
+	Files []string `json:"files"`
+}
+
+type deployConfig struct {
+	logger       *slog.Logger
+	outputDir    string
+	endpoint     string
+	region       string
+	bucket       string
+	prefix       string
+	headersFile  string
+	distribution string
+	// cloudFront is the CloudFront API endpoint.
+	cloudFront string
+	prune      bool
+	dryRun     bool
+}
+
+// localObject is one file of the build output as it should appear in the
+// bucket.
+type localObject struct {
+	Key          string
+	Path         string
+	MD5          string
+	ContentType  string
+	CacheControl string
+}
+
+// runDeploy implements `generate deploy`: it uploads the files listed in the
+// build manifest whose content differs from the object in an S3-compatible
+// bucket, optionally deletes objects the build did not write and
+// invalidates the changed HTML paths on CloudFront. Files in the output
+// directory that the last build did not write, such as pages of deleted
+// posts, are not uploaded.
+func runDeploy(args []string) error {
+	dc := deployConfig{logger: slog.Default(), cloudFront: cloudFrontHost}
+	fset := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	fset.StringVar(&dc.outputDir, "out", "public", "Build output directory to upload")
+	fset.StringVar(&dc.bucket, "bucket", "", "Target bucket name")
+	fset.StringVar(&dc.prefix, "prefix", "", "Key prefix inside the bucket")
+	fset.StringVar(&dc.region, "region", firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"), "Bucket region (defaults to AWS_REGION)")
+	fset.StringVar(&dc.endpoint, "endpoint", os.Getenv("AWS_ENDPOINT_URL"), "S3-compatible endpoint URL (defaults to AWS_ENDPOINT_URL, then AWS S3 for -region)")
+	fset.StringVar(&dc.headersFile, "headers", "headers.yaml", "Optional YAML map of path patterns to headers, as used by -headersFormat")
+	fset.StringVar(&dc.distribution, "distribution", "", "CloudFront distribution ID to invalidate changed HTML paths on")
+	fset.BoolVar(&dc.prune, "prune", false, "Delete remote objects that the last build did not write")
+	fset.BoolVar(&dc.dryRun, "dryRun", false, "Print the planned uploads, deletions and invalidations without changing anything")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: generate deploy -bucket name [flags]")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if dc.bucket == "" {
+		fset.Usage()
+		return errors.New("deploy: -bucket is required")
+	}
+	if dc.endpoint == "" {
+		dc.endpoint = "https://s3." + dc.region + ".amazonaws.com"
+	}
+	dc.endpoint = strings.TrimRight(dc.endpoint, "/")
+	dc.prefix = strings.Trim(dc.prefix, "/")
+
+	creds, err := credentialsFromEnv()
+	if err != nil {
+		return fmt.Errorf("deploy: %w", err)
+	}
+	s3 := &s3Client{endpoint: dc.endpoint, region: dc.region, bucket: dc.bucket, creds: creds}
+	return deploy(context.Background(), dc, s3)
+}
+
+func deploy(ctx context.Context, dc deployConfig, s3 *s3Client) error {
+	rules, err := headerPolicy(dc.headersFile)
+	if err != nil {
+		return err
+	}
+	local, err := localObjects(dc, rules)
+	if err != nil {
+		return err
+	}
+	remote, err := s3.list(ctx, dc.prefix)
+	if err != nil {
+		return fmt.Errorf("deploy: list %s: %w", dc.bucket, err)
+	}
+
+	var uploads []localObject
+	for _, obj := range local {
+		changed, err := s3.changed(ctx, obj, remote)
+		if err != nil {
+			return fmt.Errorf("deploy: %s: %w", obj.Key, err)
+		}
+		if changed {
+			uploads = append(uploads, obj)
+		}
+	}
+	var deletes []string
+	if dc.prune {
+		present := make(map[string]bool, len(local))
+		for _, obj := range local {
+			present[obj.Key] = true
+		}
+		for key := range remote {
+			if !present[key] && !skipDeployPath(objectPath(dc.prefix, key)) {
+				deletes = append(deletes, key)
+			}
+		}
+		sort.Strings(deletes)
+	}
+	paths := invalidationPaths(dc.prefix, uploads)
+
+	for _, obj := range uploads {
+		fmt.Printf("upload %s (%s, %s)\n", obj.Key, obj.ContentType, obj.CacheControl)
+		if dc.dryRun {
+			continue
+		}
+		if err := s3.put(ctx, obj); err != nil {
+			return fmt.Errorf("deploy: upload %s: %w", obj.Key, err)
+		}
+	}
+	for _, key := range deletes {
+		fmt.Printf("delete %s\n", key)
+		if dc.dryRun {
+			continue
+		}
+		if err := s3.delete(ctx, key); err != nil {
+			return fmt.Errorf("deploy: delete %s: %w", key, err)
+		}
+	}
+	if dc.distribution != "" && len(paths) > 0 {
+		fmt.Printf("invalidate %s\n", strings.Join(paths, " "))
+		if !dc.dryRun {
+			if err := invalidateCloudFront(ctx, dc.cloudFront, s3.creds, dc.distribution, paths); err != nil {
+				return fmt.Errorf("deploy: invalidate %s: %w", dc.distribution, err)
+			}
+		}
+	}
+	if dc.dryRun {
+		dc.logger.Info(fmt.Sprintf("배포 계획(-dryRun): 업로드 %d개, 삭제 %d개, 변경 없음 %d개", len(uploads), len(deletes), len(local)-len(uploads)))
+		return nil
+	}
+	dc.logger.Info(fmt.Sprintf("배포: 업로드 %d개, 삭제 %d개, 변경 없음 %d개", len(uploads), len(deletes), len(local)-len(uploads)))
+	return nil
+}
+
+// skipDeployPath reports whether a path relative to the output directory is
+// host configuration or build state rather than site content. Such files
+// are neither uploaded nor deleted by -prune.
+func skipDeployPath(rel string) bool {
+	switch rel {
+	case "_headers", "_redirects", "CNAME", ".nojekyll", buildLockName, buildManifestName:
+		return true
+	}
+	// Temporary files of a build lock being taken.
+	return strings.HasPrefix(rel, buildLockName+"-")
+}
+
+// objectPath returns key relative to the deploy prefix.
+func objectPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, prefix+"/")
+}
+
+// writeBuildManifest lists the files written by this build in the build
+// manifest, for deploy.
+func writeBuildManifest(cfg config) error {
+	manifest := buildManifest{Files: []string{}}
+	for _, p := range cfg.out.filesIn(cfg.outputDir) {
+		rel, err := filepath.Rel(cfg.outputDir, p)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, filepath.ToSlash(rel))
+	}
+	return cfg.out.writeJSON(filepath.Join(cfg.outputDir, buildManifestName), manifest)
+}
+
+// readBuildManifest returns the files listed in the build manifest of dir.
+func readBuildManifest(dir string) ([]string, error) {
+	file := filepath.Join(dir, buildManifestName)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("deploy: no build manifest %s: build the site first", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("deploy: read %s: %w", file, err)
+	}
+	var manifest buildManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("deploy: parse %s: %w", file, err)
+	}
+	return manifest.Files, nil
+}
+
+// localObjects hashes every file of the build manifest and attaches the
+// Content-Type and Cache-Control it should be served with.
+func localObjects(dc deployConfig, rules headerRules) ([]localObject, error) {
+	files, err := readBuildManifest(dc.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	var objs []localObject
+	for _, rel := range files {
+		if skipDeployPath(rel) {
+			continue
+		}
+		p := filepath.Join(dc.outputDir, filepath.FromSlash(rel))
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("deploy: read %s: %w", p, err)
+		}
+		sum := md5.Sum(data)
+		contentType := mime.TypeByExtension(path.Ext(rel))
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		objs = append(objs, localObject{
+			Key:          path.Join(dc.prefix, rel),
+			Path:         p,
+			MD5:          hex.EncodeToString(sum[:]),
+			ContentType:  contentType,
+			CacheControl: rules.match("/" + rel)["Cache-Control"],
+		})
+	}
+	return objs, nil
+}
+
+// invalidationPaths returns the site paths of changed HTML objects. An
+// index.html is invalidated under its directory URL as well.
+func invalidationPaths(prefix string, changed []localObject) []string {
+	var paths []string
+	for _, obj := range changed {
+		if path.Ext(obj.Key) != ".html" {
+			continue
+		}
+		p := "/" + strings.TrimPrefix(obj.Key, prefix+"/")
+		if prefix == "" {
+			p = "/" + obj.Key
+		}
+		paths = append(paths, p)
+		if path.Base(p) == "index.html" {
+			paths = append(paths, strings.TrimSuffix(p, "index.html"))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// s3Client speaks the small part of the S3 REST API deploy needs, using
+// path-style URLs so any S3-compatible endpoint works.
+type s3Client struct {
+	endpoint string
+	region   string
+	bucket   string
+	creds    awsCredentials
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the ETag of every object under prefix, keyed by object key.
+func (c *s3Client) list(ctx context.Context, prefix string) (map[string]string, error) {
+	objects := make(map[string]string)
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			q.Set("prefix", prefix+"/")
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		body, err := c.do(ctx, http.MethodGet, "", q, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res listBucketResult
+		if err := xml.Unmarshal(body, &res); err != nil {
+			return nil, fmt.Errorf("decode listing: %w", err)
+		}
+		for _, obj := range res.Contents {
+			objects[obj.Key] = strings.Trim(obj.ETag, `"`)
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+// changed reports whether obj differs from the object in the bucket. An
+// ETag equal to the MD5 settles it; otherwise, as for multipart and SSE-KMS
+// uploads whose ETag is not the MD5, the MD5 deploy stored in the object's
+// metadata decides.
+func (c *s3Client) changed(ctx context.Context, obj localObject, etags map[string]string) (bool, error) {
+	etag, ok := etags[obj.Key]
+	if !ok {
+		return true, nil
+	}
+	if etag == obj.MD5 {
+		return false, nil
+	}
+	header, err := c.head(ctx, obj.Key)
+	if err != nil {
+		return false, err
+	}
+	return header.Get(md5MetaHeader) != obj.MD5, nil
+}
+
+func (c *s3Client) head(ctx context.Context, key string) (http.Header, error) {
+	u, err := c.objectURL(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	header, _, err := signedRequest(ctx, http.MethodHead, u, nil, nil, c.creds, c.region, "s3")
+	return header, err
+}
+
+func (c *s3Client) put(ctx context.Context, obj localObject) error {
+	data, err := os.ReadFile(obj.Path)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {obj.ContentType}}
+	header.Set(md5MetaHeader, obj.MD5)
+	if obj.CacheControl != "" {
+		header.Set("Cache-Control", obj.CacheControl)
+	}
+	_, err = c.do(ctx, http.MethodPut, obj.Key, nil, header, data)
+	return err
+}
+
+func (c *s3Client) delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	return err
+}
+
+func (c *s3Client) do(ctx context.Context, method, key string, q url.Values, header http.Header, body []byte) ([]byte, error) {
+	u, err := c.objectURL(key, q)
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := signedRequest(ctx, method, u, header, body, c.creds, c.region, "s3")
+	return data, err
+}
+
+// objectURL returns the path-style URL of key, or of the bucket itself when
+// key is empty.
+func (c *s3Client) objectURL(key string, q url.Values) (*url.URL, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", c.endpoint, err)
+	}
+	u.Path = "/" + c.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = awsEscape(u.Path, false)
+	u.RawQuery = canonicalQuery(q)
+	return u, nil
+}
+
+type invalidationBatch struct {
+	XMLName xml.Name `xml:"http://cloudfront.amazonaws.com/doc/2020-05-31/ InvalidationBatch"`
+	Paths   struct {
+		Quantity int      `xml:"Quantity"`
+		Items    []string `xml:"Items>Path"`
+	} `xml:"Paths"`
+	CallerReference string `xml:"CallerReference"`
+}
+
+func invalidateCloudFront(ctx context.Context, endpoint string, creds awsCredentials, distribution string, paths []string) error {
+	var batch invalidationBatch
+	batch.Paths.Quantity = len(paths)
+	batch.Paths.Items = paths
+	batch.CallerReference = fmt.Sprintf("generate-%d", time.Now().UnixNano())
+	body, err := xml.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(endpoint + "/" + cloudFrontAPI + "/distribution/" + url.PathEscape(distribution) + "/invalidation")
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {"application/xml"}}
+	_, _, err = signedRequest(ctx, http.MethodPost, u, header, body, creds, cloudFrontZone, "cloudfront")
+	return err
+}
+
+// signedRequest sends a SigV4-signed request and returns the response
+// header and body. Any status outside 2xx is an error.
+func signedRequest(ctx context.Context, method string, u *url.URL, header http.Header, body []byte, creds awsCredentials, region, service string) (http.Header, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, deployTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	signV4(req, creds, region, service, sha256Hex(body), time.Now())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return resp.Header, data, nil
+}
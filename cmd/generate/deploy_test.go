@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSkipDeployPathUnderPrefix(t *testing.T) {
+	tests := map[string]bool{
+		"blog/_headers":         true,
+		"blog/_redirects":       true,
+		"blog/CNAME":            true,
+		"blog/.nojekyll":        true,
+		"blog/.build.lock":      true,
+		"blog/index.html":       false,
+		"blog/tags/_redirects/": false,
+	}
+	for key, want := range tests {
+		if got := skipDeployPath(objectPath("blog", key)); got != want {
+			t.Errorf("skipDeployPath(objectPath(%q)) = %v, want %v", key, got, want)
+		}
+	}
+	if !skipDeployPath(objectPath("", "_headers")) {
+		t.Error("_headers without a prefix is not skipped")
+	}
+}
+
+// fakeS3 is an in-memory S3 bucket and CloudFront API serving path-style
+// requests for one bucket.
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	// requests lists "METHOD key" for every PUT and DELETE.
+	requests      []string
+	invalidations [][]string
+}
+
+type fakeObject struct {
+	data         []byte
+	etag         string
+	md5          string
+	contentType  string
+	cacheControl string
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{bucket: bucket, objects: make(map[string]*fakeObject)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+// add stores an object as if uploaded by another tool, with a plain MD5
+// ETag and no metadata.
+func (f *fakeS3) add(key, data string) {
+	sum := md5.Sum([]byte(data))
+	f.objects[key] = &fakeObject{data: []byte(data), etag: hex.EncodeToString(sum[:])}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/"+cloudFrontAPI+"/distribution/") {
+		var batch invalidationBatch
+		if err := xml.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.invalidations = append(f.invalidations, batch.Paths.Items)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		var res listBucketResult
+		prefix := r.URL.Query().Get("prefix")
+		for k, obj := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				res.Contents = append(res.Contents, struct {
+					Key  string `xml:"Key"`
+					ETag string `xml:"ETag"`
+				}{k, `"` + obj.etag + `"`})
+			}
+		}
+		xml.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodHead:
+		obj := f.objects[key]
+		if obj == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if obj.md5 != "" {
+			w.Header().Set(md5MetaHeader, obj.md5)
+		}
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		sum := md5.Sum(data)
+		f.objects[key] = &fakeObject{
+			data:         data,
+			etag:         hex.EncodeToString(sum[:]),
+			md5:          r.Header.Get(md5MetaHeader),
+			contentType:  r.Header.Get("Content-Type"),
+			cacheControl: r.Header.Get("Cache-Control"),
+		}
+		f.requests = append(f.requests, "PUT "+key)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		f.requests = append(f.requests, "DELETE "+key)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+// takeRequests returns and clears the recorded PUT and DELETE requests.
+func (f *fakeS3) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reqs := f.requests
+	sort.Strings(reqs)
+	f.requests = nil
+	return reqs
+}
+
+func testDeploy(t *testing.T, cfg config, srv *httptest.Server, dryRun bool) {
+	t.Helper()
+	dc := deployConfig{
+		logger:       cfg.logger,
+		outputDir:    cfg.outputDir,
+		endpoint:     srv.URL,
+		region:       "us-east-1",
+		bucket:       "site",
+		prefix:       "blog",
+		headersFile:  cfg.headersFile,
+		distribution: "DIST",
+		cloudFront:   srv.URL,
+		prune:        true,
+		dryRun:       dryRun,
+	}
+	s3 := &s3Client{endpoint: srv.URL, region: dc.region, bucket: dc.bucket, creds: awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}}
+	if err := deploy(context.Background(), dc, s3); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+}
+
+func TestDeployPlan(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.redirectsFormat = hostNetlify
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, cfg)
+	// A page of a deleted post, left in the output directory.
+	stale := filepath.Join(cfg.outputDir, "gone", "index.html")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("<p>gone</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s3, srv := newFakeS3(t, "site")
+	s3.add("blog/gone/index.html", "<p>gone</p>\n")
+	s3.add("blog/_redirects", "/a /b 301\n")
+	s3.add("other/index.html", "not ours")
+
+	manifest, err := readBuildManifest(cfg.outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wantFirst []string
+	for _, rel := range manifest {
+		if !skipDeployPath(rel) {
+			wantFirst = append(wantFirst, "PUT blog/"+rel)
+		}
+	}
+	wantFirst = append(wantFirst, "DELETE blog/gone/index.html")
+	sort.Strings(wantFirst)
+
+	t.Run("dry run", func(t *testing.T) {
+		testDeploy(t, cfg, srv, true)
+		if reqs := s3.takeRequests(); len(reqs) > 0 {
+			t.Errorf("dry run changed the bucket: %v", reqs)
+		}
+		if len(s3.invalidations) > 0 {
+			t.Errorf("dry run invalidated %v", s3.invalidations)
+		}
+	})
+
+	t.Run("first deploy", func(t *testing.T) {
+		testDeploy(t, cfg, srv, false)
+		if got := s3.takeRequests(); !slices.Equal(got, wantFirst) {
+			t.Errorf("requests =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantFirst, "\n"))
+		}
+		page := s3.objects["blog/hello/index.html"]
+		if page == nil {
+			t.Fatal("hello/index.html was not uploaded")
+		}
+		if page.contentType != "text/html; charset=utf-8" || page.cacheControl != "public, max-age=0, must-revalidate" {
+			t.Errorf("hello/index.html served as %q with %q", page.contentType, page.cacheControl)
+		}
+		if s3.objects["blog/_redirects"] == nil || s3.objects["other/index.html"] == nil {
+			t.Error("host files or objects outside the prefix were deleted")
+		}
+		if len(s3.invalidations) != 1 || !slices.Contains(s3.invalidations[0], "/hello/") || !slices.Contains(s3.invalidations[0], "/hello/index.html") {
+			t.Errorf("invalidations = %v, want one holding /hello/ and /hello/index.html", s3.invalidations)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		s3.invalidations = nil
+		testDeploy(t, cfg, srv, false)
+		if reqs := s3.takeRequests(); len(reqs) > 0 {
+			t.Errorf("unchanged site sent %v", reqs)
+		}
+		if len(s3.invalidations) > 0 {
+			t.Errorf("unchanged site invalidated %v", s3.invalidations)
+		}
+	})
+
+	t.Run("ETag is not the MD5", func(t *testing.T) {
+		// Multipart and SSE-KMS uploads get an ETag that is not the MD5.
+		for _, obj := range s3.objects {
+			obj.etag = "0123456789abcdef0123456789abcdef-2"
+		}
+		testDeploy(t, cfg, srv, false)
+		if reqs := s3.takeRequests(); len(reqs) > 0 {
+			t.Errorf("objects with their MD5 in metadata were sent again: %v", reqs)
+		}
+	})
+
+	t.Run("changed post", func(t *testing.T) {
+		writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nnew body\n")
+		buildSite(t, cfg)
+		s3.invalidations = nil
+		testDeploy(t, cfg, srv, false)
+		if reqs := s3.takeRequests(); !slices.Contains(reqs, "PUT blog/hello/index.html") || slices.Contains(reqs, "PUT blog/gone/index.html") {
+			t.Errorf("requests = %v, want hello/index.html uploaded and nothing of gone/", reqs)
+		}
+		if len(s3.invalidations) != 1 || !slices.Contains(s3.invalidations[0], "/hello/") {
+			t.Errorf("invalidations = %v, want /hello/", s3.invalidations)
+		}
+	})
+}
+
+func TestDeployWithoutManifest(t *testing.T) {
+	cfg := testConfig(t)
+	if err := os.MkdirAll(cfg.outputDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := localObjects(deployConfig{outputDir: cfg.outputDir}, defaultHeaderRules())
+	if err == nil || !strings.Contains(err.Error(), "build the site first") {
+		t.Fatalf("err = %v, want a missing build manifest", err)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// envFlags maps flags to the environment variables that can supply them,
+// for containerized builds that would rather not pass arguments.
+var envFlags = map[string]string{
+	"baseURL":     "BLOG_BASE_URL",
+	"title":       "BLOG_TITLE",
+	"env":         "BLOG_ENV",
+	"out":         "BLOG_OUTPUT_DIR",
+	"content":     "BLOG_CONTENT_DIR",
+	"language":    "BLOG_LANGUAGE",
+	"description": "BLOG_DESCRIPTION",
+}
+
+// applyEnvFlags sets every flag in vars that was not given on the command
+// line from its environment variable, when that is non-empty. Precedence is
+// explicit flag, then environment, then the flag's default. Values go
+// through fs.Set, so they are parsed exactly like flag arguments.
+func applyEnvFlags(fs *flag.FlagSet, vars map[string]string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if explicit[name] {
+			continue
+		}
+		value := os.Getenv(vars[name])
+		if value == "" {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%s: %w", vars[name], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import "fmt"
+
+// FrontMatterError reports front matter in a content file that could not be
+// parsed.
+type FrontMatterError struct {
+	Path string
+	Err  error
+}
+
+func (e *FrontMatterError) Error() string {
+	return fmt.Sprintf("front matter %s: %v", e.Path, e.Err)
+}
+
+func (e *FrontMatterError) Unwrap() error { return e.Err }
+
+// TemplateError reports a page template that failed to parse. Path is empty
+// for the built-in fallback templates.
+type TemplateError struct {
+	Name string
+	Path string
+	Err  error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("parse built-in %s template: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("parse %s template %s: %v", e.Name, e.Path, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error { return e.Err }
+
+// RenderError reports a page that failed while executing its template. Kind
+// names the page type ("post", "tag", "index", ...), Slug identifies the page
+// when there is more than one of that kind and Target is the output file.
+type RenderError struct {
+	Kind   string
+	Slug   string
+	Target string
+	Err    error
+}
+
+func (e *RenderError) Error() string {
+	if e.Slug == "" {
+		return fmt.Sprintf("render %s %s: %v", e.Kind, e.Target, e.Err)
+	}
+	return fmt.Sprintf("render %s %s (%s): %v", e.Kind, e.Slug, e.Target, e.Err)
+}
+
+func (e *RenderError) Unwrap() error { return e.Err }
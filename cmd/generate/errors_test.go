@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBrokenPostTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+		// want are substrings the build error must contain.
+		want []string
+	}{
+		{
+			name: "execution error",
+			tpl:  `{{ define "content" }}{{ .Post.NoSuchField }}{{ end }}`,
+			want: []string{"post.html", "hello-world"},
+		},
+		{
+			name: "parse error",
+			tpl:  `{{ define "content" }}{{ if .Post }}{{ end }`,
+			want: []string{"post.html"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			copyTemplates(t, &cfg)
+			if err := os.WriteFile(filepath.Join(cfg.templateDir, "post.html"), []byte(tt.tpl), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			writeContent(t, cfg, "hello-world.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+
+			err := run(context.Background(), cfg)
+			if err == nil {
+				t.Fatal("run succeeded with a broken post.html")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not name %s", err, want)
+				}
+			}
+			var renderErr *RenderError
+			var tplErr *TemplateError
+			if !errors.As(err, &renderErr) && !errors.As(err, &tplErr) {
+				t.Errorf("error %T is neither a RenderError nor a TemplateError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	stdhtml "html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// summaryMarkdown renders summaries and descriptions for excerpts; it is
+// plain GFM, without the heading and link rewriting of post bodies.
+func summaryMarkdown() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+}
+
+// postExcerpt returns p's summary or description rendered from markdown to
+// plain text or, with asHTML, to HTML. Posts without either fall back to
+// the first length runes of the body text, or "" when length is 0.
+func postExcerpt(md goldmark.Markdown, p post, length int, asHTML bool) (string, error) {
+	summary := strings.TrimSpace(firstNonEmpty(p.Summary, p.Description))
+	if summary == "" {
+		if length <= 0 {
+			return "", nil
+		}
+		excerpt := truncateRunes(strings.Join(strings.Fields(plainText(p.doc, p.source, plainTextOptions{})), " "), length)
+		if asHTML {
+			return "<p>" + stdhtml.EscapeString(excerpt) + "</p>", nil
+		}
+		return excerpt, nil
+	}
+
+	htmlSummary, doc, err := renderMarkdown(md, []byte(summary))
+	if err != nil {
+		return "", err
+	}
+	if asHTML {
+		return strings.TrimSpace(htmlSummary.String()), nil
+	}
+	return strings.Join(strings.Fields(plainText(doc, []byte(summary), plainTextOptions{})), " "), nil
+}
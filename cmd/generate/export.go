@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// postExport is the full-content export written to <slug>/index.json by
+// -exportJSON. It carries enough to rebuild the post elsewhere.
+type postExport struct {
+	Slug        string            `json:"slug"`
+	Source      string            `json:"source"`
+	FrontMatter exportFrontMatter `json:"frontMatter"`
+	HTML        string            `json:"html"`
+	Markdown    string            `json:"markdown"`
+}
+
+// exportFrontMatter is the front matter after defaults and trimming have
+// been applied, so consumers never see the raw YAML quirks.
+type exportFrontMatter struct {
+	Title       string   `json:"title"`
+	Date        string   `json:"date,omitempty"`
+	Tags        []string `json:"tags"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Draft       bool     `json:"draft"`
+	Unlisted    bool     `json:"unlisted"`
+}
+
+func writePostExport(cfg config, p post) error {
+	fm := exportFrontMatter{
+		Title:       p.Title,
+		Tags:        make([]string, 0, len(p.Tags)),
+		Summary:     p.Summary,
+		Description: p.Description,
+		Draft:       p.Draft,
+		Unlisted:    p.Unlisted,
+	}
+	if !p.Date.IsZero() {
+		fm.Date = p.Date.Format(time.RFC3339)
+	}
+	for _, t := range p.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			fm.Tags = append(fm.Tags, t)
+		}
+	}
+	export := postExport{
+		Slug:        p.Slug,
+		Source:      filepath.ToSlash(p.SourcePath),
+		FrontMatter: fm,
+		HTML:        string(p.ContentHTML),
+		Markdown:    string(p.ContentRaw),
+	}
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug), "index.json")
+	return cfg.out.writeJSON(target, export)
+}
+
+// writeMarkdownSource writes the post's markdown to <slug>/index.md exactly
+// as it was read: the body alone, or the whole file when front matter is
+// requested.
+func writeMarkdownSource(cfg config, p post) error {
+	data := p.ContentRaw
+	if cfg.exportMarkdownFrontMatter {
+		data = p.src
+	}
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug), "index.md")
+	if err := cfg.out.writeIfChanged(target, data); err != nil {
+		return fmt.Errorf("write markdown %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportKeepsShortcodes(t *testing.T) {
+	const body = "Results:\n\n{{< csv \"results.csv\" >}}\n"
+	const src = "---\ntitle: Results\ndate: 2024-05-03\n---\n" + body
+	cfg := testConfig(t)
+	cfg.csvHeader = true
+	cfg.exportJSON = true
+	cfg.exportMarkdown = true
+	writeContent(t, cfg, "results.md", src)
+	if err := os.WriteFile(filepath.Join(cfg.contentDirs[0], "results.csv"), []byte("name,score\nkim,3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buildSite(t, cfg)
+
+	if got := readOutput(t, cfg, "results/index.md"); got != body {
+		t.Errorf("index.md = %q, want the body as written %q", got, body)
+	}
+	var export postExport
+	if err := json.Unmarshal([]byte(readOutput(t, cfg, "results/index.json")), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Markdown != body {
+		t.Errorf("markdown = %q, want %q", export.Markdown, body)
+	}
+	if !strings.Contains(export.HTML, "<td>kim</td>") {
+		t.Errorf("html does not hold the expanded table:\n%s", export.HTML)
+	}
+
+	cfg.exportJSON = false
+	cfg.exportMarkdownFrontMatter = true
+	buildSite(t, cfg)
+	if got := readOutput(t, cfg, "results/index.md"); got != src {
+		t.Errorf("index.md with front matter = %q, want %q", got, src)
+	}
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+)
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XMLNSAtom string     `xml:"xmlns:atom,attr"`
+	XMLNSFH   string     `xml:"xmlns:fh,attr,omitempty"`
+	XMLNSDC   string     `xml:"xmlns:dc,attr,omitempty"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language,omitempty"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	AtomLinks     []atomLink `xml:"atom:link"`
+	Archive       *struct{}  `xml:"fh:archive"`
+	Items         []rssItem  `xml:"item"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// feedHistoryNS is the RFC 5005 namespace of the fh:archive marker.
+const feedHistoryNS = "http://purl.org/syndication/history/1.0"
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description feedText `xml:"description"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Updated     string   `xml:"atom:updated,omitempty"`
+}
+
+// feedText is element text that is written as a CDATA section when it holds
+// HTML, so readers get the markup instead of entity-escaped tags.
+type feedText struct {
+	Text  string
+	CDATA bool
+}
+
+func (t feedText) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if t.CDATA {
+		return e.EncodeElement(struct {
+			Text string `xml:",cdata"`
+		}{t.Text}, start)
+	}
+	return e.EncodeElement(t.Text, start)
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr,omitempty"`
+	Value       string `xml:",chardata"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name    `xml:"opml"`
+	Version string      `xml:"version,attr"`
+	Head    opmlHead    `xml:"head"`
+	Body    []opmlEntry `xml:"body>outline"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlEntry struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+const (
+	feedDescriptionPlain = "plain"
+	feedDescriptionHTML  = "html"
+)
+
+const (
+	mainFeedPath       = "/feeds/rss.xml"
+	opmlPath           = "/feeds/index.opml"
+	defaultTitle       = "썸고 블로그"
+	defaultDescription = "DevOps 엔지니어 썸고(thumbgo)의 블로그"
+)
+
+// feedArchivePath is the site path of archive page n of the main feed. Pages
+// are numbered from the oldest posts, so a page never changes once full.
+func feedArchivePath(n int) string {
+	return fmt.Sprintf("/feeds/rss-page-%d.xml", n)
+}
+
+// authorFeedPath is the site path of the feed for a single author.
+func authorFeedPath(slug string) string {
+	return "/feeds/authors/" + slug + ".xml"
+}
+
+// tagFeedPath is the site path of the feed for a single tag.
+func tagFeedPath(slug string) string {
+	return "/feeds/tags/" + slug + ".xml"
+}
+
+// feedLink describes a feed for <link rel="alternate"> autodiscovery.
+type feedLink struct {
+	Title string
+	Type  string
+	URL   string
+}
+
+// siteFeeds lists the feeds every page advertises.
+func siteFeeds(cfg config) []feedLink {
+	return []feedLink{{Title: cfg.title, Type: "application/rss+xml", URL: feedBase(cfg) + mainFeedPath}}
+}
+
+// tagFeedLinks lists the extra feeds advertised on a tag's pages: its own
+// feed, when -tagFeeds writes one.
+func tagFeedLinks(cfg config, tag tagGroup) []feedLink {
+	if !cfg.tagFeeds || len(feedPosts(tag.Posts)) == 0 {
+		return nil
+	}
+	return []feedLink{{
+		Title: fmt.Sprintf("%s - %s", cfg.title, tag.Name),
+		Type:  "application/rss+xml",
+		URL:   feedBase(cfg) + tagFeedPath(tag.Slug),
+	}}
+}
+
+// feedBase is the absolute URL prefix shared by every feed and OPML link.
+func feedBase(cfg config) string {
+	if cfg.baseURL == "" {
+		return defaultBaseURL
+	}
+	return cfg.baseURL
+}
+
+// feedPosts returns the posts eligible for feeds. Undated posts are left out
+// rather than being published as "Mon, 01 Jan 0001", as are posts marked
+// `feed: false`. Item counts and lastBuildDate follow from this list.
+func feedPosts(posts []post) []post {
+	var out []post
+	for _, p := range posts {
+		if p.Date.IsZero() || !p.Feed {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func renderRSS(cfg config, posts []post) error {
+	if cfg.feedArchiveSize <= 0 {
+		return writeRSS(cfg, mainFeedPath, cfg.title, cfg.description, feedBase(cfg), posts)
+	}
+	return renderArchivedRSS(cfg, posts)
+}
+
+// renderArchivedRSS writes the main feed plus RFC 5005 archive documents.
+// Posts are split into pages of -feedArchive items counted from the oldest,
+// so only the newest page changes as posts are added. The main feed stays a
+// plain feed of the latest posts that points at the newest archive page.
+// Only the archived-feed links of RFC 5005 are used: current, prev-archive
+// and next-archive.
+func renderArchivedRSS(cfg config, posts []post) error {
+	posts = feedPosts(posts)
+	if len(posts) == 0 {
+		return nil
+	}
+	base := feedBase(cfg)
+	link := func(n int, rel string) atomLink {
+		href := base + mainFeedPath
+		if n > 0 {
+			href = base + feedArchivePath(n)
+		}
+		return atomLink{Href: href, Rel: rel, Type: "application/rss+xml"}
+	}
+
+	size := cfg.feedArchiveSize
+	pages := (len(posts) + size - 1) / size
+	for n := 1; n <= pages; n++ {
+		// posts is newest first; page n holds the n-th oldest block.
+		end := len(posts) - (n-1)*size
+		start := max(end-size, 0)
+		links := []atomLink{link(0, "current")}
+		if n > 1 {
+			links = append(links, link(n-1, "prev-archive"))
+		}
+		if n < pages {
+			links = append(links, link(n+1, "next-archive"))
+		}
+		title := fmt.Sprintf("%s (%d)", cfg.title, n)
+		if err := writeFeed(cfg, feedArchivePath(n), title, cfg.description, base, posts[start:end], links, true); err != nil {
+			return err
+		}
+	}
+
+	latest := latestFeedPosts(cfg, posts)
+	links := []atomLink{link(pages, "prev-archive")}
+	return writeFeed(cfg, mainFeedPath, cfg.title, cfg.description, base, latest, links, false)
+}
+
+// renderTagFeeds writes one feed per tag next to the main feed.
+func renderTagFeeds(cfg config, tags []tagGroup) error {
+	base := feedBase(cfg)
+	for _, tag := range tags {
+		title := fmt.Sprintf("%s - %s", cfg.title, tag.Name)
+		description := uiLabel(cfg.language, "tagFeed", tag.Name)
+		if err := writeRSS(cfg, tagFeedPath(tag.Slug), title, description, base+tagPath(tag.Slug), tag.Posts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxFeedItems caps the number of items in a regular feed.
+const maxFeedItems = 50
+
+// buildAuthorGroups groups non-draft posts by their author, keyed by the
+// authors file key when there is one and otherwise by the same slug rules as
+// tags. Posts without an author are left out.
+func buildAuthorGroups(posts []post) []tagGroup {
+	bySlug := make(map[string]*tagGroup)
+	var order []string
+	for _, p := range posts {
+		name := strings.TrimSpace(p.Author)
+		if name == "" || p.Draft {
+			continue
+		}
+		slug := tagSlug(name)
+		if p.AuthorProfile != nil {
+			slug = p.AuthorProfile.Key
+		}
+		g, ok := bySlug[slug]
+		if !ok {
+			g = &tagGroup{Name: name, Slug: slug}
+			bySlug[slug] = g
+			order = append(order, slug)
+		}
+		g.Posts = append(g.Posts, p)
+	}
+	sort.Strings(order)
+	groups := make([]tagGroup, 0, len(order))
+	for _, slug := range order {
+		groups = append(groups, *bySlug[slug])
+	}
+	return groups
+}
+
+// renderAuthorFeeds writes one feed per author next to the main feed.
+// Authors without feed-eligible posts get no feed.
+func renderAuthorFeeds(cfg config, authors []tagGroup) error {
+	base := feedBase(cfg)
+	for _, a := range authors {
+		title := fmt.Sprintf("%s - %s", cfg.title, a.Name)
+		description := uiLabel(cfg.language, "authorFeed", a.Name)
+		if err := writeRSS(cfg, authorFeedPath(a.Slug), title, description, base+"/", a.Posts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRSS encodes posts as an RSS 2.0 channel at the site path feedPath.
+// Nothing is written when no post is eligible for feeds.
+func writeRSS(cfg config, feedPath, title, description, link string, posts []post) error {
+	posts = feedPosts(posts)
+	if len(posts) == 0 {
+		return nil
+	}
+	return writeFeed(cfg, feedPath, title, description, link, latestFeedPosts(cfg, posts), nil, false)
+}
+
+// feedUpdated returns when p was last significantly updated: its front
+// matter lastmod when that is at least -feedUpdateThreshold after its date,
+// and the zero time otherwise. Git commit dates are not used: any commit
+// touching the file, such as a typo fix, would resurface the post, while
+// setting lastmod is the author saying the change matters to readers.
+func feedUpdated(cfg config, p post) time.Time {
+	if p.LastMod.IsZero() || p.LastMod.Sub(p.Date) < cfg.feedUpdateThreshold {
+		return time.Time{}
+	}
+	return p.LastMod
+}
+
+// latestFeedPosts picks the items of a non-archive feed from posts, which
+// are newest first. With -feedResurface significantly updated posts are
+// ordered by their update time and so move back to the top; archive pages
+// never reorder, keeping them stable.
+func latestFeedPosts(cfg config, posts []post) []post {
+	if cfg.feedResurface {
+		posts = append([]post(nil), posts...)
+		sort.SliceStable(posts, func(i, j int) bool {
+			return feedSortDate(cfg, posts[i]).After(feedSortDate(cfg, posts[j]))
+		})
+	}
+	return posts[:min(len(posts), maxFeedItems)]
+}
+
+// feedSortDate is the time an item counts as new in a feed.
+func feedSortDate(cfg config, p post) time.Time {
+	return firstNonZero(feedUpdated(cfg, p), p.Date)
+}
+
+// writeFeed writes exactly posts as an RSS channel at feedPath, with an
+// atom self link followed by links. archive marks the document as an RFC
+// 5005 archive page.
+func writeFeed(cfg config, feedPath, title, description, link string, posts []post, links []atomLink, archive bool) error {
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(feedPath, "/")))
+	base := feedBase(cfg)
+	lastBuild := posts[0].Date
+	if !archive {
+		lastBuild = feedLastBuild(cfg, posts)
+	}
+
+	channel := rssChannel{
+		Title:         title,
+		Link:          link,
+		Description:   description,
+		Language:      cfg.language,
+		LastBuildDate: formatRFC1123(lastBuild, cfg.location),
+		AtomLinks: append([]atomLink{{
+			Href: base + feedPath,
+			Rel:  "self",
+			Type: "application/rss+xml",
+		}}, links...),
+	}
+	if archive {
+		channel.Archive = &struct{}{}
+	}
+
+	md := summaryMarkdown()
+	for _, p := range posts {
+		link := base + "/" + p.Slug + "/"
+		description, err := feedDescription(md, cfg, p)
+		if err != nil {
+			return fmt.Errorf("feed description %s: %w", p.SourcePath, err)
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       p.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: link},
+			PubDate:     formatRFC1123(p.Date, cfg.location),
+			Description: description,
+			Creator:     p.Author,
+		})
+		// Archive pages are immutable, so they never carry updates.
+		if updated := feedUpdated(cfg, p); !archive && !updated.IsZero() {
+			channel.Items[len(channel.Items)-1].Updated = updated.In(cfg.location).Format(time.RFC3339)
+		}
+	}
+
+	feed := rssFeed{
+		Version:   "2.0",
+		XMLNSAtom: "http://www.w3.org/2005/Atom",
+		Channel:   channel,
+	}
+	if archive {
+		feed.XMLNSFH = feedHistoryNS
+	}
+	for _, item := range channel.Items {
+		if item.Creator != "" {
+			feed.XMLNSDC = "http://purl.org/dc/elements/1.1/"
+			break
+		}
+	}
+
+	return cfg.out.writeXML(target, feed)
+}
+
+// feedLastBuild is the newest date or significant update among posts.
+func feedLastBuild(cfg config, posts []post) time.Time {
+	var last time.Time
+	for _, p := range posts {
+		if d := feedSortDate(cfg, p); d.After(last) {
+			last = d
+		}
+	}
+	return last
+}
+
+// feedDescription returns the item description for p, an excerpt of at
+// most -feedExcerptLength runes as plain text or, in HTML mode, as HTML in
+// CDATA.
+func feedDescription(md goldmark.Markdown, cfg config, p post) (feedText, error) {
+	asHTML := cfg.feedDescriptionMode == feedDescriptionHTML
+	text, err := postExcerpt(md, p, cfg.feedExcerptLength, asHTML)
+	if err != nil {
+		return feedText{}, err
+	}
+	return feedText{Text: text, CDATA: asHTML}, nil
+}
+
+// renderOPML lists the main feed and every tag feed so readers can
+// subscribe to all of them at once. It must run after the feeds it lists.
+func renderOPML(cfg config, tags, authors []tagGroup) error {
+	base := feedBase(cfg)
+	doc := opmlDocument{
+		Version: "2.0",
+		Head:    opmlHead{Title: cfg.title},
+		Body: []opmlEntry{{
+			Type:    "rss",
+			Text:    cfg.title,
+			Title:   cfg.title,
+			XMLURL:  base + mainFeedPath,
+			HTMLURL: base + "/",
+		}},
+	}
+	if cfg.tagFeeds {
+		for _, tag := range tags {
+			if len(feedPosts(tag.Posts)) == 0 {
+				continue
+			}
+			title := fmt.Sprintf("%s - %s", cfg.title, tag.Name)
+			doc.Body = append(doc.Body, opmlEntry{
+				Type:    "rss",
+				Text:    title,
+				Title:   title,
+				XMLURL:  base + tagFeedPath(tag.Slug),
+				HTMLURL: base + tagPath(tag.Slug),
+			})
+		}
+	}
+	if cfg.authorFeeds {
+		for _, a := range authors {
+			if len(feedPosts(a.Posts)) == 0 {
+				continue
+			}
+			title := fmt.Sprintf("%s - %s", cfg.title, a.Name)
+			doc.Body = append(doc.Body, opmlEntry{
+				Type:    "rss",
+				Text:    title,
+				Title:   title,
+				XMLURL:  base + authorFeedPath(a.Slug),
+				HTMLURL: base + "/",
+			})
+		}
+	}
+
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(opmlPath, "/")))
+	return cfg.out.writeXML(target, doc)
+}
+
+// writeXML encodes v with an XML header and writes it to target when the
+// bytes differ from what is already there.
+func (o *buildOutput) writeXML(target string, v any) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode %s: %w", target, err)
+	}
+	if err := enc.Flush(); err != nil {
+		return fmt.Errorf("flush %s: %w", target, err)
+	}
+
+	if err := ensureDir(filepath.Dir(target)); err != nil {
+		return err
+	}
+	if err := o.writeText(target, buf.Bytes()); err != nil {
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+	return nil
+}
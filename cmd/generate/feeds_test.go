@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 5, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestFeedUpdated(t *testing.T) {
+	cfg := config{feedUpdateThreshold: 24 * time.Hour}
+	tests := []struct {
+		name string
+		p    post
+		want time.Time
+	}{
+		{"no lastmod", post{Date: day(1)}, time.Time{}},
+		{"small fix", post{Date: day(1), LastMod: day(1).Add(2 * time.Hour)}, time.Time{}},
+		{"at the threshold", post{Date: day(1), LastMod: day(2)}, day(2)},
+		{"git date without lastmod", post{Date: day(1), Git: gitInfo{LastCommitDate: day(5)}}, time.Time{}},
+		{"lastmod wins over git", post{Date: day(1), LastMod: day(4), Git: gitInfo{LastCommitDate: day(5)}}, day(4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feedUpdated(cfg, tt.p); !got.Equal(tt.want) {
+				t.Errorf("feedUpdated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestFeedPosts(t *testing.T) {
+	posts := []post{
+		{Slug: "new", Date: day(10)},
+		{Slug: "updated", Date: day(5), LastMod: day(20)},
+		// Committed again for a typo fix, without touching lastmod.
+		{Slug: "fixed", Date: day(3), Git: gitInfo{LastCommitDate: day(25)}},
+		{Slug: "old", Date: day(1)},
+	}
+	cfg := config{feedUpdateThreshold: 24 * time.Hour}
+	if got := slugsOf(latestFeedPosts(cfg, posts)); got != "new updated fixed old" {
+		t.Errorf("without -feedResurface = %q, want date order", got)
+	}
+	cfg.feedResurface = true
+	if got := slugsOf(latestFeedPosts(cfg, posts)); got != "updated new fixed old" {
+		t.Errorf("with -feedResurface = %q, want only the post with a later lastmod first", got)
+	}
+	if got := slugsOf(posts); got != "new updated fixed old" {
+		t.Errorf("latestFeedPosts reordered its input to %q", got)
+	}
+}
+
+func slugsOf(posts []post) string {
+	slugs := make([]string, len(posts))
+	for i, p := range posts {
+		slugs[i] = p.Slug
+	}
+	return strings.Join(slugs, " ")
+}
+
+type testFeed struct {
+	Channel struct {
+		LastBuildDate string `xml:"lastBuildDate"`
+		Items         []struct {
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func readFeed(t *testing.T, cfg config, feedPath string) testFeed {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(cfg.outputDir, filepath.FromSlash(feedPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed testFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func TestWriteRSS(t *testing.T) {
+	tests := []struct {
+		name      string
+		posts     []post
+		items     []string
+		lastBuild time.Time
+		updated   string
+	}{
+		{
+			name: "undated and feed: false posts are left out",
+			posts: []post{
+				{Title: "Undated", Slug: "undated", Feed: true},
+				{Title: "Quiet", Slug: "quiet", Date: day(9), Feed: false},
+				{Title: "Shown", Slug: "shown", Date: day(3), Feed: true},
+			},
+			items:     []string{"Shown"},
+			lastBuild: day(3),
+		},
+		{
+			name: "significant update sets lastBuildDate",
+			posts: []post{
+				{Title: "New", Slug: "new", Date: day(10), Feed: true},
+				{Title: "Revised", Slug: "revised", Date: day(5), LastMod: day(12), Feed: true},
+			},
+			items:     []string{"New", "Revised"},
+			lastBuild: day(12),
+			updated:   day(12).Format(time.RFC3339),
+		},
+		{
+			name: "small fix does not",
+			posts: []post{
+				{Title: "New", Slug: "new", Date: day(10), Feed: true},
+				{Title: "Fixed", Slug: "fixed", Date: day(5), LastMod: day(5).Add(time.Hour), Feed: true},
+			},
+			items:     []string{"New", "Fixed"},
+			lastBuild: day(10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			// The posts have no parsed body to excerpt.
+			cfg.feedExcerptLength = 0
+			if err := writeRSS(cfg, mainFeedPath, cfg.title, cfg.description, feedBase(cfg), tt.posts); err != nil {
+				t.Fatal(err)
+			}
+			feed := readFeed(t, cfg, mainFeedPath)
+			var titles []string
+			var updated string
+			for _, item := range feed.Channel.Items {
+				titles = append(titles, item.Title)
+				updated += item.Updated
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.items, ",") {
+				t.Errorf("items = %v, want %v", titles, tt.items)
+			}
+			if want := formatRFC1123(tt.lastBuild, cfg.location); feed.Channel.LastBuildDate != want {
+				t.Errorf("lastBuildDate = %q, want %q", feed.Channel.LastBuildDate, want)
+			}
+			if updated != tt.updated {
+				t.Errorf("atom:updated = %q, want %q", updated, tt.updated)
+			}
+		})
+	}
+}
+
+func TestWriteRSSWithoutEligiblePosts(t *testing.T) {
+	cfg := testConfig(t)
+	posts := []post{{Title: "Undated", Slug: "undated", Feed: true}}
+	if err := writeRSS(cfg, mainFeedPath, cfg.title, cfg.description, feedBase(cfg), posts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "feeds", "rss.xml")); err == nil {
+		t.Error("feed written without any eligible post")
+	}
+}
+
+func TestArchivedRSSLinks(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.feedArchiveSize = 2
+	cfg.feedExcerptLength = 0
+	var posts []post
+	for d := 5; d >= 1; d-- {
+		posts = append(posts, post{Title: fmt.Sprint("Post ", d), Slug: fmt.Sprint("post-", d), Date: day(d), Feed: true})
+	}
+	if err := renderRSS(cfg, posts); err != nil {
+		t.Fatal(err)
+	}
+
+	base := feedBase(cfg)
+	tests := []struct {
+		feedPath string
+		links    []string
+		archive  bool
+	}{
+		{mainFeedPath, []string{"self " + mainFeedPath, "prev-archive " + feedArchivePath(3)}, false},
+		{feedArchivePath(1), []string{"self " + feedArchivePath(1), "current " + mainFeedPath, "next-archive " + feedArchivePath(2)}, true},
+		{feedArchivePath(2), []string{"self " + feedArchivePath(2), "current " + mainFeedPath, "prev-archive " + feedArchivePath(1), "next-archive " + feedArchivePath(3)}, true},
+		{feedArchivePath(3), []string{"self " + feedArchivePath(3), "current " + mainFeedPath, "prev-archive " + feedArchivePath(2)}, true},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(cfg.outputDir, filepath.FromSlash(tt.feedPath)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Links []struct {
+				Rel  string `xml:"rel,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"channel>link"`
+		}
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		var links []string
+		for _, l := range doc.Links {
+			if l.Rel != "" {
+				links = append(links, l.Rel+" "+strings.TrimPrefix(l.Href, base))
+			}
+		}
+		if !slices.Equal(links, tt.links) {
+			t.Errorf("%s links = %q, want %q", tt.feedPath, links, tt.links)
+		}
+		hasNS := strings.Contains(string(data), `xmlns:fh=`)
+		hasMarker := strings.Contains(string(data), "<fh:archive>")
+		if hasNS != tt.archive || hasMarker != tt.archive {
+			t.Errorf("%s: xmlns:fh %v, fh:archive %v, want both %v", tt.feedPath, hasNS, hasMarker, tt.archive)
+		}
+	}
+}
+
+func TestTagIndexLinksOPML(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.baseURL = "https://x.dev/blog"
+	cfg.tagFeeds = true
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\ntags: [go]\n---\nbody\n")
+	buildSite(t, cfg)
+
+	readOutput(t, cfg, "feeds/index.opml")
+	if page := readOutput(t, cfg, "tags/index.html"); !strings.Contains(page, `<a href="/blog/feeds/index.opml" type="text/x-opml">`) {
+		t.Errorf("tag index does not link the OPML export:\n%s", page)
+	}
+}
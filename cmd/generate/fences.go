@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// fenceRenderers maps a fenced code block language to the command that turns
+// its contents into SVG, e.g. "dot" -> "dot -Tsvg".
+type fenceRenderers map[string]string
+
+func (f fenceRenderers) String() string {
+	var parts []string
+	for lang, cmd := range f {
+		parts = append(parts, lang+"="+cmd)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set parses a single "lang=command" pair so the flag can be repeated.
+func (f fenceRenderers) Set(v string) error {
+	lang, cmd, ok := strings.Cut(v, "=")
+	lang, cmd = strings.TrimSpace(lang), strings.TrimSpace(cmd)
+	if !ok || lang == "" || cmd == "" {
+		return fmt.Errorf("want lang=command, got %q", v)
+	}
+	f[lang] = cmd
+	return nil
+}
+
+// fenceRendererExtension renders fenced blocks whose language has a
+// configured command by piping the block through it and inlining the SVG.
+// Blocks whose command fails are rendered as ordinary code blocks.
+type fenceRendererExtension struct {
+	logger   *slog.Logger
+	commands fenceRenderers
+	timeout  time.Duration
+}
+
+func (e *fenceRendererExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&fenceNodeRenderer{fenceRendererExtension: e}, 100),
+	))
+}
+
+type fenceNodeRenderer struct {
+	*fenceRendererExtension
+}
+
+func (r *fenceNodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	fallback := defaultRendererFunc(ast.KindFencedCodeBlock)
+	reg.Register(ast.KindFencedCodeBlock, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		n := node.(*ast.FencedCodeBlock)
+		lang := string(n.Language(source))
+		cmd, ok := r.commands[lang]
+		if !ok {
+			return fallback(w, source, node, entering)
+		}
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		svg, err := r.run(cmd, blockLines(n, source))
+		if err != nil {
+			r.logger.Warn(fmt.Sprintf("%s 블록을 %q로 렌더링하지 못해 코드 블록으로 남깁니다: %v", lang, cmd, err))
+			if _, err := fallback(w, source, node, true); err != nil {
+				return ast.WalkStop, err
+			}
+			if _, err := fallback(w, source, node, false); err != nil {
+				return ast.WalkStop, err
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		fmt.Fprintf(w, "<div class=\"diagram diagram-%s\">%s</div>\n", util.EscapeHTML([]byte(lang)), svg)
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+func (r *fenceNodeRenderer) run(command string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	args := strings.Fields(command)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", r.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	out := stdout.Bytes()
+	start := bytes.Index(out, []byte("<svg"))
+	if start == -1 {
+		return nil, errors.New("command produced no <svg> element")
+	}
+	return bytes.TrimSpace(out[start:]), nil
+}
+
+// defaultRendererFunc returns goldmark's stock HTML renderer for kind so an
+// override can fall back to it.
+func defaultRendererFunc(kind ast.NodeKind) renderer.NodeRendererFunc {
+	c := &rendererFuncCapture{kind: kind}
+	html.NewRenderer(html.WithUnsafe()).RegisterFuncs(c)
+	return c.fn
+}
+
+type rendererFuncCapture struct {
+	kind ast.NodeKind
+	fn   renderer.NodeRendererFunc
+}
+
+func (c *rendererFuncCapture) Register(kind ast.NodeKind, fn renderer.NodeRendererFunc) {
+	if kind == c.kind {
+		c.fn = fn
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFenceRenderers(t *testing.T) {
+	for _, name := range []string{"cat", "false", "sleep"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s is not installed", name)
+		}
+	}
+	cfg := testConfig(t)
+	cfg.fenceTimeout = 200 * time.Millisecond
+	for _, v := range []string{"svg=cat", "broken=false", "text=cat", "slow=sleep 5"} {
+		if err := cfg.fenceRenderers.Set(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeContent(t, cfg, "diagrams.md", "---\ntitle: Diagrams\ndate: 2024-05-03\n---\n"+
+		"```svg\n<?xml version=\"1.0\"?>\n<svg viewBox=\"0 0 1 1\"></svg>\n```\n\n"+
+		"```broken\nA -> B\n```\n\n"+
+		"```text\nno drawing\n```\n\n"+
+		"```slow\nC -> D\n```\n\n"+
+		"```go\nfmt.Println(\"<svg>\")\n```\n")
+	buildSite(t, cfg)
+
+	page := readOutput(t, cfg, "diagrams/index.html")
+	for _, want := range []string{
+		`<div class="diagram diagram-svg"><svg viewBox="0 0 1 1"></svg></div>`,
+		`<pre><code class="language-broken">A -&gt; B`,
+		`<pre><code class="language-text">no drawing`,
+		`<pre><code class="language-slow">C -&gt; D`,
+		`<pre><code class="language-go">fmt.Println(&quot;&lt;svg&gt;&quot;)`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page has no %s:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<?xml") {
+		t.Error("the XML declaration before <svg> was kept")
+	}
+}
+
+func TestFenceRenderersSet(t *testing.T) {
+	f := fenceRenderers{}
+	if err := f.Set(" dot = dot -Tsvg "); err != nil {
+		t.Fatal(err)
+	}
+	if f["dot"] != "dot -Tsvg" {
+		t.Errorf("dot = %q, want dot -Tsvg", f["dot"])
+	}
+	for _, v := range []string{"dot", "=dot", "dot="} {
+		if err := f.Set(v); err == nil {
+			t.Errorf("Set(%q) succeeded", v)
+		}
+	}
+}
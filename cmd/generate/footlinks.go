@@ -0,0 +1,47 @@
+package main
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// footLink is one external link of a post, listed after the body with
+// -collectLinks so printed copies keep the URLs.
+type footLink struct {
+	Text string
+	URL  string
+}
+
+// collectLinks scans the rendered body for external http(s) links in
+// document order. Links to the site itself, relative and anchor links are
+// left out, and each URL is listed once with its first text.
+func collectLinks(baseURL string, content template.HTML) []footLink {
+	var own string
+	if u, err := url.Parse(baseURL); err == nil {
+		own = strings.ToLower(u.Host)
+	}
+	var (
+		links []footLink
+		seen  = make(map[string]bool)
+		text  strings.Builder
+		href  string
+	)
+	for _, t := range tokenizeHTML([]byte(content)) {
+		switch {
+		case t.Tag == "a" && t.Type == htmlStartTag:
+			href = strings.TrimSpace(t.Attrs["href"])
+			text.Reset()
+		case t.Tag == "a" && t.Type == htmlEndTag && href != "":
+			u, err := url.Parse(href)
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") && strings.ToLower(u.Host) != own && !seen[href] {
+				seen[href] = true
+				links = append(links, footLink{Text: strings.Join(strings.Fields(text.String()), " "), URL: href})
+			}
+			href = ""
+		case t.Type == htmlText && href != "":
+			text.WriteString(t.Text)
+		}
+	}
+	return links
+}
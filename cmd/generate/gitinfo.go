@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitInfo is the commit history of a post's source file, filled in by
+// -gitInfo. Every field is empty when the file is not tracked.
+type gitInfo struct {
+	FirstAuthor     string
+	FirstCommitDate time.Time
+	LastCommit      string
+	LastCommitDate  time.Time
+	HistoryURL      string
+}
+
+// attachGitInfo fills in post.Git with a single `git log` per repository
+// instead of one call per file. Posts outside a git checkout, or a missing
+// git binary, leave the fields empty.
+func attachGitInfo(ctx context.Context, cfg config, posts []post) {
+	byRoot := make(map[string][]int)
+	for i, p := range posts {
+		abs, err := filepath.Abs(p.SourcePath)
+		if err != nil {
+			continue
+		}
+		if root := repoRoot(filepath.Dir(abs)); root != "" {
+			byRoot[root] = append(byRoot[root], i)
+		}
+	}
+
+	for root, idxs := range byRoot {
+		history, err := gitFileHistory(ctx, root)
+		if err != nil {
+			cfg.logger.Warn(fmt.Sprintf("%s의 git 기록을 읽지 못했습니다: %v", root, err))
+			continue
+		}
+		for _, i := range idxs {
+			abs, _ := filepath.Abs(posts[i].SourcePath)
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				continue
+			}
+			info, ok := history[filepath.ToSlash(rel)]
+			if !ok {
+				continue
+			}
+			if repo := posts[i].repo; repo != "" && cfg.githubBranch != "" {
+				info.HistoryURL = "https://github.com/" + repo + "/commits/" + cfg.githubBranch + "/" + filepath.ToSlash(rel)
+			}
+			posts[i].Git = info
+		}
+	}
+}
+
+// gitFileHistory walks the log of the repository at root, newest first, and
+// records for every file the last commit touching it and the author and
+// date of the first one. Renames are not followed.
+func gitFileHistory(ctx context.Context, root string) (map[string]gitInfo, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", root, "log", "--format=%x1e%H%x1f%an%x1f%aI", "--name-only", "--no-renames")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[string]gitInfo)
+	var hash, author string
+	var date time.Time
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "\x1e") {
+			fields := strings.Split(strings.TrimPrefix(line, "\x1e"), "\x1f")
+			if len(fields) != 3 {
+				continue
+			}
+			hash, author = fields[0], fields[1]
+			date, _ = time.Parse(time.RFC3339, fields[2])
+			continue
+		}
+		if line == "" || hash == "" {
+			continue
+		}
+		info, seen := history[line]
+		if !seen {
+			info.LastCommit, info.LastCommitDate = hash, date
+		}
+		info.FirstAuthor, info.FirstCommitDate = author, date
+		history[line] = info
+	}
+	return history, sc.Err()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// headerRules maps a path pattern such as "/assets/*" to the headers sent
+// for it.
+type headerRules map[string]map[string]string
+
+// defaultHeaderRules is the cache policy used when headers.yaml says
+// nothing else: HTML is revalidated on every request, feeds are cached
+// briefly and assets for an hour. Asset names are not fingerprinted, so they
+// cannot be marked immutable. Netlify and Cloudflare Pages merge every rule
+// that matches a path, so no two of these patterns match the same file.
+func defaultHeaderRules() headerRules {
+	noCache := "public, max-age=0, must-revalidate"
+	return headerRules{
+		"/":         {"Cache-Control": noCache},
+		"/*/":       {"Cache-Control": noCache},
+		"/*.html":   {"Cache-Control": noCache},
+		"/feeds/*":  {"Cache-Control": "public, max-age=600"},
+		"/assets/*": {"Cache-Control": "public, max-age=3600"},
+	}
+}
+
+// loadHeaderRules reads custom rules from file. A missing file is not an
+// error.
+func loadHeaderRules(file string) (headerRules, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read headers %s: %w", file, err)
+	}
+	var rules headerRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse headers %s: %w", file, err)
+	}
+	for pattern := range rules {
+		if !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("headers %s: pattern %q must start with /", file, pattern)
+		}
+	}
+	return rules, nil
+}
+
+// headerPolicy returns the default rules merged with those in file. A custom
+// header replaces the default of the same name for that pattern; an empty
+// value removes it.
+func headerPolicy(file string) (headerRules, error) {
+	custom, err := loadHeaderRules(file)
+	if err != nil {
+		return nil, err
+	}
+	rules := defaultHeaderRules()
+	for pattern, headers := range custom {
+		if rules[pattern] == nil {
+			rules[pattern] = make(map[string]string, len(headers))
+		}
+		for name, value := range headers {
+			if strings.TrimSpace(value) == "" {
+				delete(rules[pattern], name)
+				continue
+			}
+			rules[pattern][name] = value
+		}
+	}
+	return rules, nil
+}
+
+// match returns the headers that apply to urlPath. A "*" in a pattern
+// matches any run of characters, "/" included. Matching patterns are applied
+// shortest first, so for the S3 metadata a more specific pattern's value
+// replaces a broader one's; hosts reading _headers combine every match
+// instead, so custom rules should not set one header on overlapping
+// patterns.
+func (r headerRules) match(urlPath string) map[string]string {
+	var patterns []string
+	for pattern := range r {
+		if patternMatches(pattern, urlPath) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) < len(patterns[j]) })
+	headers := make(map[string]string)
+	for _, pattern := range patterns {
+		for name, value := range r[pattern] {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// patternMatches reports whether urlPath matches pattern, which may hold one
+// "*" splat.
+func patternMatches(pattern, urlPath string) bool {
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok {
+		return pattern == urlPath
+	}
+	return len(urlPath) >= len(prefix)+len(suffix) && strings.HasPrefix(urlPath, prefix) && strings.HasSuffix(urlPath, suffix)
+}
+
+// renderNetlifyHeaders writes a _headers file (read by Netlify and
+// Cloudflare Pages) from headerPolicy.
+func renderNetlifyHeaders(cfg config) error {
+	rules, err := headerPolicy(cfg.headersFile)
+	if err != nil {
+		return err
+	}
+	if cfg.cspPolicy != "" {
+		if rules["/*"] == nil {
+			rules["/*"] = make(map[string]string)
+		}
+		if _, ok := rules["/*"]["Content-Security-Policy"]; !ok {
+			rules["/*"]["Content-Security-Policy"] = cfg.cspPolicy
+		}
+	}
+	patterns := make([]string, 0, len(rules))
+	for pattern := range rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var b strings.Builder
+	for _, pattern := range patterns {
+		headers := rules[pattern]
+		if len(headers) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString(pattern + "\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %s: %s\n", name, headers[name])
+		}
+	}
+	target := filepath.Join(cfg.outputDir, "_headers")
+	if err := cfg.out.writeText(target, []byte(b.String())); err != nil {
+		return fmt.Errorf("write headers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestDefaultHeaderRulesCacheControl(t *testing.T) {
+	rules := defaultHeaderRules()
+	tests := map[string]string{
+		"/":                 "public, max-age=0, must-revalidate",
+		"/hello-world/":     "public, max-age=0, must-revalidate",
+		"/404.html":         "public, max-age=0, must-revalidate",
+		"/feeds/rss.xml":    "public, max-age=600",
+		"/assets/style.css": "public, max-age=3600",
+	}
+	for urlPath, want := range tests {
+		if got := rules.match(urlPath)["Cache-Control"]; got != want {
+			t.Errorf("match(%q) Cache-Control = %q, want %q", urlPath, got, want)
+		}
+	}
+	for pattern := range rules {
+		for other := range rules {
+			if pattern != other && patternMatches(pattern, "/assets/style.css") && patternMatches(other, "/assets/style.css") {
+				t.Errorf("patterns %q and %q both match an asset", pattern, other)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// numberHeadingsKey lets a single parse opt out of heading numbering; set
+// it to false in the parser context.
+var numberHeadingsKey = parser.NewContextKey()
+
+// headingNumberExtension prefixes h2–h4 headings with hierarchical numbers
+// ("1", "1.1", "1.1.1"). A shallower heading resets the deeper counters and
+// h1 is left alone. Heading IDs are assigned before it runs, so anchors do
+// not change.
+type headingNumberExtension struct{}
+
+func (headingNumberExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(headingNumberer{}, 100),
+	))
+}
+
+type headingNumberer struct{}
+
+func (headingNumberer) Transform(doc *ast.Document, _ text.Reader, pc parser.Context) {
+	if on, ok := pc.Get(numberHeadingsKey).(bool); ok && !on {
+		return
+	}
+	var counters [3]int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if h.Level < 2 || h.Level > 4 {
+			return ast.WalkSkipChildren, nil
+		}
+		i := h.Level - 2
+		counters[i]++
+		for j := i + 1; j < len(counters); j++ {
+			counters[j] = 0
+		}
+		parts := make([]string, 0, i+1)
+		for _, c := range counters[:i+1] {
+			parts = append(parts, strconv.Itoa(c))
+		}
+		h.InsertBefore(h, h.FirstChild(), ast.NewString([]byte(strings.Join(parts, ".")+" ")))
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// headingIDs generates heading anchors like GitHub does: lower-cased
+// letters and digits of any script, with spaces turned into dashes, so a
+// Korean heading keeps a readable anchor instead of goldmark's ASCII-only
+// one. Repeated anchors get -1, -2, ... suffixes. It is per document; use
+// one for each parser context.
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]bool)}
+}
+
+func (s *headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	var b strings.Builder
+	lastDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(string(value))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			lastDash = false
+		case r == '-' || unicode.IsSpace(r):
+			if b.Len() > 0 && !lastDash {
+				b.WriteRune('-')
+				lastDash = true
+			}
+		}
+	}
+	id := strings.TrimRight(b.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+	unique := id
+	for i := 1; s.used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+	s.used[unique] = true
+	return []byte(unique)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
+
+// heading is one heading of a post, in document order.
+type heading struct {
+	Level int
+	// Text is the heading's plain text, with inline markup flattened.
+	Text string
+	// ID is the anchor assigned by the parser's auto heading IDs.
+	ID string
+}
+
+// extractHeadings lists every heading in doc, including those nested in
+// blockquotes and lists.
+func extractHeadings(doc ast.Node, src []byte) []heading {
+	var out []heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		var id string
+		if v, ok := h.AttributeString("id"); ok {
+			if b, ok := v.([]byte); ok {
+				id = string(b)
+			}
+		}
+		out = append(out, heading{
+			Level: h.Level,
+			Text:  inlineText(h, src, plainTextOptions{}),
+			ID:    id,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return out
+}
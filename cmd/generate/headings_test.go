@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestExtractHeadings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []heading
+	}{
+		{
+			name: "korean",
+			src:  "## 설치 방법\n\n### Go 모듈 **설정**\n",
+			want: []heading{
+				{Level: 2, Text: "설치 방법", ID: "설치-방법"},
+				{Level: 3, Text: "Go 모듈 설정", ID: "go-모듈-설정"},
+			},
+		},
+		{
+			name: "duplicates",
+			src:  "## Intro\n\n## Intro\n\n## 소개\n\n## 소개\n\n## Intro\n",
+			want: []heading{
+				{Level: 2, Text: "Intro", ID: "intro"},
+				{Level: 2, Text: "Intro", ID: "intro-1"},
+				{Level: 2, Text: "소개", ID: "소개"},
+				{Level: 2, Text: "소개", ID: "소개-1"},
+				{Level: 2, Text: "Intro", ID: "intro-2"},
+			},
+		},
+		{
+			name: "punctuation only",
+			src:  "## ???\n\n## !!!\n",
+			want: []heading{
+				{Level: 2, Text: "???", ID: "heading"},
+				{Level: 2, Text: "!!!", ID: "heading-1"},
+			},
+		},
+	}
+	md := newMarkdown(testConfig(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+			_, doc, err := renderMarkdown(md, []byte(tt.src), parser.WithContext(pc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := extractHeadings(doc, []byte(tt.src))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d headings %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("heading %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
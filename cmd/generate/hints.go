@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// resourceHint is one <link> hint for the page head.
+type resourceHint struct {
+	Rel         string
+	Href        string
+	As          string
+	Type        string
+	CrossOrigin bool
+}
+
+// preloadHints turns the -preload paths into preload hints, inferring "as"
+// and "type" from the extension. Fonts are always fetched in CORS mode, so
+// their hints need crossorigin to be reused. Duplicates are dropped.
+func preloadHints(paths []string) []resourceHint {
+	var hints []resourceHint
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		h := resourceHint{Rel: "preload", Href: p}
+		switch strings.ToLower(path.Ext(p)) {
+		case ".css":
+			h.As, h.Type = "style", "text/css"
+		case ".js":
+			h.As, h.Type = "script", "text/javascript"
+		case ".woff2":
+			h.As, h.Type, h.CrossOrigin = "font", "font/woff2", true
+		case ".woff":
+			h.As, h.Type, h.CrossOrigin = "font", "font/woff", true
+		case ".ttf":
+			h.As, h.Type, h.CrossOrigin = "font", "font/ttf", true
+		case ".otf":
+			h.As, h.Type, h.CrossOrigin = "font", "font/otf", true
+		default:
+			h.As = "fetch"
+			h.CrossOrigin = true
+		}
+		hints = append(hints, h)
+	}
+	return hints
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cnameDomain returns the domain to write to the CNAME file, or "" when no
+// file should be written: -cname=off, a github.io host (served without a
+// custom domain) or the placeholder base URL.
+func cnameDomain(cfg config) string {
+	switch cname := strings.TrimSpace(cfg.cname); {
+	case strings.EqualFold(cname, "off"):
+		return ""
+	case cname != "":
+		return cname
+	}
+	if cfg.baseURL == defaultBaseURL {
+		return ""
+	}
+	u, err := url.Parse(cfg.baseURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if isGitHubIOHost(host) {
+		return ""
+	}
+	return host
+}
+
+func isGitHubIOHost(host string) bool {
+	return host == "github.io" || strings.HasSuffix(host, ".github.io")
+}
+
+// githubPagesBuild reports whether the output is meant for GitHub Pages,
+// either explicitly via -githubPages or because the site lives on github.io.
+func githubPagesBuild(cfg config) bool {
+	if cfg.githubPages {
+		return true
+	}
+	u, err := url.Parse(cfg.baseURL)
+	return err == nil && isGitHubIOHost(strings.ToLower(u.Hostname()))
+}
+
+// renderNoJekyll writes the empty .nojekyll marker that stops GitHub Pages
+// from running Jekyll, and warns about output that Jekyll would have hidden
+// anyway so it can be renamed for other hosts too.
+func renderNoJekyll(cfg config) error {
+	target := filepath.Join(cfg.outputDir, ".nojekyll")
+	if err := cfg.out.writeIfChanged(target, nil); err != nil {
+		return fmt.Errorf("write .nojekyll: %w", err)
+	}
+	return filepath.WalkDir(cfg.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), "_") {
+			rel, _ := filepath.Rel(cfg.outputDir, p)
+			cfg.logger.Warn(fmt.Sprintf("%s는 밑줄(_)로 시작합니다. GitHub Pages의 Jekyll 처리에서 무시될 수 있습니다.", filepath.ToSlash(rel)))
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+		}
+		return nil
+	})
+}
+
+// renderCNAME writes the GitHub Pages CNAME file at the output root.
+func renderCNAME(cfg config) error {
+	domain := cnameDomain(cfg)
+	if domain == "" {
+		return nil
+	}
+	target := filepath.Join(cfg.outputDir, "CNAME")
+	if err := cfg.out.writeText(target, []byte(domain)); err != nil {
+		return fmt.Errorf("write CNAME: %w", err)
+	}
+	return nil
+}
+
+// editURL links to the GitHub editor for a post's source file in repo, or
+// returns "" when no repository is configured or the file is not inside a
+// git checkout.
+func editURL(cfg config, repo, sourcePath string) string {
+	if repo == "" || cfg.githubBranch == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return ""
+	}
+	root := repoRoot(filepath.Dir(abs))
+	if root == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return "https://github.com/" + repo + "/edit/" + cfg.githubBranch + "/" + (&url.URL{Path: filepath.ToSlash(rel)}).EscapedPath()
+}
+
+// repoRoot returns the nearest ancestor of dir containing .git, or "".
+func repoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
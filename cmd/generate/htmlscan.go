@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	stdhtml "html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type htmlTokenType int
+
+const (
+	htmlStartTag htmlTokenType = iota
+	htmlEndTag
+	htmlText
+	// htmlRawText is the content of a script or style element; Tag names
+	// the element.
+	htmlRawText
+)
+
+// htmlToken is one tag or run of text of a generated page. Comments and
+// doctypes are skipped; the content of a CDATA section is text.
+type htmlToken struct {
+	Type  htmlTokenType
+	Tag   string
+	Attrs map[string]string
+	// Text is the entity-decoded text of an htmlText token, or the content
+	// of an htmlRawText token as written.
+	Text string
+	Line int
+	// Pos and End are the byte offsets of the token in the input.
+	Pos, End int
+}
+
+var (
+	htmlTagPattern  = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9-]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*)\s*/?>`)
+	htmlAttrPattern = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+)
+
+// tokenizeHTML splits our own generated markup into tokens. It is not a
+// general HTML parser, only enough for the post-build page scan and for
+// reading and rewriting rendered post bodies.
+func tokenizeHTML(data []byte) []htmlToken {
+	var toks []htmlToken
+	line, pos := 1, 0
+	// consume moves past the next n bytes, recording them as a token of typ
+	// unless text is blank.
+	consume := func(typ htmlTokenType, tag string, n int, text string) {
+		if strings.TrimSpace(text) != "" {
+			toks = append(toks, htmlToken{Type: typ, Tag: tag, Text: text, Line: line, Pos: pos, End: pos + n})
+		}
+		line += bytes.Count(data[:n], []byte("\n"))
+		pos += n
+		data = data[n:]
+	}
+	// indexEnd returns the length of data up to and including end, or all
+	// of it when end does not occur.
+	indexEnd := func(end string) int {
+		if j := bytes.Index(data, []byte(end)); j >= 0 {
+			return j + len(end)
+		}
+		return len(data)
+	}
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '<')
+		if i < 0 {
+			i = len(data)
+		}
+		consume(htmlText, "", i, stdhtml.UnescapeString(string(data[:i])))
+		if len(data) == 0 {
+			break
+		}
+
+		switch {
+		case bytes.HasPrefix(data, []byte("<![CDATA[")):
+			n := indexEnd("]]>")
+			consume(htmlText, "", n, strings.TrimSuffix(string(data[len("<![CDATA["):n]), "]]>"))
+			continue
+		case bytes.HasPrefix(data, []byte("<!--")):
+			consume(htmlText, "", indexEnd("-->"), "")
+			continue
+		case bytes.HasPrefix(data, []byte("<!")):
+			consume(htmlText, "", indexEnd(">"), "")
+			continue
+		}
+		m := htmlTagPattern.FindSubmatchIndex(data)
+		if m == nil {
+			consume(htmlText, "", 1, "<")
+			continue
+		}
+		tok := htmlToken{Type: htmlStartTag, Tag: strings.ToLower(string(data[m[4]:m[5]])), Line: line, Pos: pos, End: pos + m[1]}
+		if m[3] > m[2] {
+			tok.Type = htmlEndTag
+		} else {
+			tok.Attrs = make(map[string]string)
+			for _, a := range htmlAttrPattern.FindAllSubmatch(data[m[6]:m[7]], -1) {
+				tok.Attrs[strings.ToLower(string(a[1]))] = stdhtml.UnescapeString(string(a[2]) + string(a[3]) + string(a[4]))
+			}
+		}
+		toks = append(toks, tok)
+		consume(htmlText, "", m[1], "")
+		if tok.Type == htmlStartTag && (tok.Tag == "script" || tok.Tag == "style") {
+			j := bytes.Index(bytes.ToLower(data), []byte("</"+tok.Tag))
+			if j < 0 {
+				j = len(data)
+			}
+			consume(htmlRawText, tok.Tag, j, string(data[:j]))
+		}
+	}
+	return toks
+}
+
+// attrValueOffset returns where the value of attribute name starts in tag,
+// the markup of one start tag, or -1 when tag has no such attribute value.
+func attrValueOffset(tag, name string) int {
+	for _, m := range htmlAttrPattern.FindAllStringSubmatchIndex(tag, -1) {
+		if !strings.EqualFold(tag[m[2]:m[3]], name) {
+			continue
+		}
+		for g := 4; g < len(m); g += 2 {
+			if m[g] >= 0 {
+				return m[g]
+			}
+		}
+		return -1
+	}
+	return -1
+}
+
+// pageCheck inspects the tokens of one generated page and returns one
+// message per finding. Checks that need every page run from the same single
+// parse so nothing is read or tokenized twice.
+type pageCheck struct {
+	Name  string
+	Check func(toks []htmlToken) []string
+}
+
+// pageScan is what scanPages collects in its single pass over the pages.
+type pageScan struct {
+	// assetRefs holds the /assets/ paths the pages reference, relative to
+	// the asset root.
+	assetRefs map[string]struct{}
+	findings  []lintFinding
+}
+
+// scanPages reads and tokenizes every HTML and XML file written by this
+// build once. It collects the asset references of both and runs checks on
+// the HTML pages, skipping rules disabled with -lintDisable. Files left in
+// the output directory by earlier builds, and the copied assets, are not
+// scanned. Findings carry the page path relative to the output directory.
+func scanPages(cfg config, checks []pageCheck) (pageScan, error) {
+	scan := pageScan{assetRefs: make(map[string]struct{})}
+	off := make(map[string]bool, len(cfg.lintDisable))
+	for _, name := range cfg.lintDisable {
+		off[name] = true
+	}
+	assetOut := filepath.Join(cfg.outputDir, "assets") + string(filepath.Separator)
+	for _, p := range cfg.out.filesIn(cfg.outputDir) {
+		ext := filepath.Ext(p)
+		if (ext != ".html" && ext != ".xml") || strings.HasPrefix(p, assetOut) {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return scan, fmt.Errorf("read page %s: %w", p, err)
+		}
+		toks := tokenizeHTML(data)
+		for _, ref := range assetRefs(cfg, toks) {
+			scan.assetRefs[ref] = struct{}{}
+		}
+		if ext != ".html" {
+			// Feeds carry post bodies as escaped or CDATA text.
+			for _, t := range toks {
+				if t.Type == htmlText && strings.Contains(t.Text, "<") {
+					for _, ref := range assetRefs(cfg, tokenizeHTML([]byte(t.Text))) {
+						scan.assetRefs[ref] = struct{}{}
+					}
+				}
+			}
+			continue
+		}
+		rel, err := filepath.Rel(cfg.outputDir, p)
+		if err != nil {
+			return scan, err
+		}
+		for _, c := range checks {
+			if off[c.Name] {
+				continue
+			}
+			for _, msg := range c.Check(toks) {
+				scan.findings = append(scan.findings, lintFinding{Path: filepath.ToSlash(rel), Rule: c.Name, Message: msg})
+			}
+		}
+	}
+	return scan, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestA11yCheckSkipsStalePages(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.a11yCheck = true
+	cfg.strictA11y = true
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	// A page left by an earlier build, without a lang attribute.
+	stale := filepath.Join(cfg.outputDir, "old", "index.html")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("<!DOCTYPE html>\n<html>\n<p><a href=\"/\">here</a></p>\n</html>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buildSite(t, cfg)
+
+	// The same markup written by this build is checked.
+	writeContent(t, cfg, "vague.md", "---\ntitle: Vague\ndate: 2024-05-04\n---\nSee [here](/hello/).\n")
+	err := run(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "lint: 1 finding(s)") {
+		t.Errorf("run error = %v, want one link-text finding", err)
+	}
+}
+
+func TestTokenizeHTML(t *testing.T) {
+	const page = "<p class=\"a\">x &amp; y</p>\n<!-- note -->\n<script>if (a < b) {}</script>\n<![CDATA[<img src=\"/i.png\">]]>"
+	var got []string
+	for _, tok := range tokenizeHTML([]byte(page)) {
+		switch tok.Type {
+		case htmlStartTag:
+			got = append(got, fmt.Sprintf("start %s %v line %d %q", tok.Tag, tok.Attrs, tok.Line, page[tok.Pos:tok.End]))
+		case htmlEndTag:
+			got = append(got, fmt.Sprintf("end %s line %d", tok.Tag, tok.Line))
+		case htmlText:
+			got = append(got, fmt.Sprintf("text %q line %d", tok.Text, tok.Line))
+		case htmlRawText:
+			got = append(got, fmt.Sprintf("raw %s %q line %d", tok.Tag, tok.Text, tok.Line))
+		}
+	}
+	want := []string{
+		`start p map[class:a] line 1 "<p class=\"a\">"`,
+		`text "x & y" line 1`,
+		`end p line 1`,
+		`start script map[] line 3 "<script>"`,
+		`raw script "if (a < b) {}" line 3`,
+		`end script line 3`,
+		`text "<img src=\"/i.png\">" line 4`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokens =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// rewriteImageSources points root-relative <img src> values in rendered HTML
+// at cdn. Remote, protocol-relative, data and relative URLs are left alone,
+// as is everything when cdn is empty.
+func rewriteImageSources(html, cdn string) string {
+	if cdn == "" {
+		return html
+	}
+	var b strings.Builder
+	last := 0
+	for _, t := range tokenizeHTML([]byte(html)) {
+		if t.Type != htmlStartTag || t.Tag != "img" || cdnURL(cdn, t.Attrs["src"]) == t.Attrs["src"] {
+			continue
+		}
+		at := attrValueOffset(html[t.Pos:t.End], "src")
+		if at < 0 {
+			continue
+		}
+		b.WriteString(html[last : t.Pos+at])
+		b.WriteString(cdn)
+		last = t.Pos + at
+	}
+	b.WriteString(html[last:])
+	return b.String()
+}
+
+// imageSources returns the src of every <img> in rendered HTML, entity
+// decoded, in document order. Images without a src are left out.
+func imageSources(html string) []string {
+	var srcs []string
+	for _, t := range tokenizeHTML([]byte(html)) {
+		if t.Type == htmlStartTag && t.Tag == "img" && t.Attrs["src"] != "" {
+			srcs = append(srcs, t.Attrs["src"])
+		}
+	}
+	return srcs
+}
+
+// cdnURL returns src on the cdn host when it is a local root-relative path.
+func cdnURL(cdn, src string) string {
+	if cdn == "" || !strings.HasPrefix(src, "/") || strings.HasPrefix(src, "//") {
+		return src
+	}
+	return cdn + src
+}
+
+// firstImage returns the destination of the first markdown image in doc, or
+// "" when the post has none.
+func firstImage(doc ast.Node) string {
+	var dest string
+	if doc == nil {
+		return dest
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			dest = string(img.Destination)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return dest
+}
+
+// thumbnailURL resolves a post image so it works from any listing page:
+// paths relative to the post are rooted under its slug, and local paths are
+// placed on the image CDN or baseURL. Remote and data URLs pass through.
+func thumbnailURL(cfg config, slug, src string) string {
+	src = strings.TrimSpace(src)
+	switch {
+	case src == "", strings.HasPrefix(src, "//"), strings.Contains(src, ":"):
+		return src
+	case !strings.HasPrefix(src, "/"):
+		src = path.Join("/", slug, src)
+	}
+	if cfg.imageCDN != "" {
+		return cdnURL(cfg.imageCDN, src)
+	}
+	return cfg.baseURL + src
+}
+
+// imageReportEntry describes one image src in images.json.
+type imageReportEntry struct {
+	Src     string   `json:"src"`
+	Local   bool     `json:"local"`
+	Size    int64    `json:"size,omitempty"`
+	Missing bool     `json:"missing,omitempty"`
+	Posts   []string `json:"posts"`
+}
+
+// renderImageReport writes images.json listing every <img> in the rendered
+// posts with the slugs using it. Paths relative to a post are reported
+// rooted under its slug. Local images are looked up in the output directory,
+// so it must run after assets have been copied.
+func renderImageReport(cfg config, posts []post) error {
+	bySrc := make(map[string]*imageReportEntry)
+	for _, p := range posts {
+		for _, src := range imageSources(string(p.ContentHTML)) {
+			if !strings.HasPrefix(src, "/") && !strings.Contains(src, ":") {
+				src = path.Join("/", p.Slug, src)
+			}
+			e, ok := bySrc[src]
+			if !ok {
+				e = &imageReportEntry{Src: src}
+				bySrc[src] = e
+			}
+			if n := len(e.Posts); n == 0 || e.Posts[n-1] != p.Slug {
+				e.Posts = append(e.Posts, p.Slug)
+			}
+		}
+	}
+
+	entries := make([]imageReportEntry, 0, len(bySrc))
+	for src, e := range bySrc {
+		if local, ok := localImagePath(cfg, src); ok {
+			e.Local = true
+			if info, err := os.Stat(filepath.Join(cfg.outputDir, filepath.FromSlash(local))); err == nil {
+				e.Size = info.Size()
+			} else {
+				e.Missing = true
+			}
+		}
+		sort.Strings(e.Posts)
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Src < entries[j].Src })
+	return cfg.out.writeJSON(filepath.Join(cfg.outputDir, "images.json"), entries)
+}
+
+// localImagePath returns the output-relative path of a root-relative image,
+// undoing the -imageCDN rewrite. Remote and data URLs report false.
+func localImagePath(cfg config, src string) (string, bool) {
+	if cfg.imageCDN != "" && strings.HasPrefix(src, cfg.imageCDN+"/") {
+		src = strings.TrimPrefix(src, cfg.imageCDN)
+	}
+	if !strings.HasPrefix(src, "/") || strings.HasPrefix(src, "//") {
+		return "", false
+	}
+	if i := strings.IndexAny(src, "?#"); i >= 0 {
+		src = src[:i]
+	}
+	return strings.TrimPrefix(path.Clean(src), "/"), true
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRewriteImageSources(t *testing.T) {
+	const cdn = "https://cdn.example.net"
+	tests := map[string]string{
+		`<p><img src="/a.png" alt="a"></p>`:                  `<p><img src="https://cdn.example.net/a.png" alt="a"></p>`,
+		`<IMG alt="b" SRC='/b.png'>`:                         `<IMG alt="b" SRC='https://cdn.example.net/b.png'>`,
+		`<img src=/c.png>`:                                   `<img src=https://cdn.example.net/c.png>`,
+		`<img data-src="/d.png" src="/d.png">`:               `<img data-src="/d.png" src="https://cdn.example.net/d.png">`,
+		`<img src="https://x.dev/e.png">`:                    `<img src="https://x.dev/e.png">`,
+		`<img src="f.png"><img src="//x.dev/f.png">`:         `<img src="f.png"><img src="//x.dev/f.png">`,
+		"<pre><code>&lt;img src=\"/g.png\"&gt;</code></pre>": "<pre><code>&lt;img src=\"/g.png\"&gt;</code></pre>",
+	}
+	for in, want := range tests {
+		if got := rewriteImageSources(in, cdn); got != want {
+			t.Errorf("rewriteImageSources(%s) =\n%s\nwant\n%s", in, got, want)
+		}
+	}
+	if got := rewriteImageSources(`<img src="/a.png">`, ""); got != `<img src="/a.png">` {
+		t.Errorf("without a cdn: %s", got)
+	}
+}
+
+func TestImageSources(t *testing.T) {
+	got := imageSources(`<img src="/a.png?w=1&amp;h=2"><img alt="none"><p>&lt;img src="/code.png"&gt;</p><img src='b.png'>`)
+	if want := []string{"/a.png?w=1&h=2", "b.png"}; !slices.Equal(got, want) {
+		t.Errorf("imageSources = %q, want %q", got, want)
+	}
+}
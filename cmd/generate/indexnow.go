@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	indexNowEndpoint = "https://api.indexnow.org/indexnow"
+	indexNowAttempts = 2
+	indexNowMaxURLs  = 10000
+)
+
+var indexNowKeyPattern = regexp.MustCompile(`^[A-Za-z0-9-]{8,128}$`)
+
+// indexNowPayload is the JSON body of an IndexNow submission.
+type indexNowPayload struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation"`
+	URLList     []string `json:"urlList"`
+}
+
+// pageDigests hashes every HTML page under outDir so a later call can tell
+// which pages a build changed.
+func pageDigests(outDir string) (map[string][sha256.Size]byte, error) {
+	digests := make(map[string][sha256.Size]byte)
+	err := filepath.WalkDir(outDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".html" {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(outDir, p)
+		if err != nil {
+			return err
+		}
+		digests[filepath.ToSlash(rel)] = sha256.Sum256(data)
+		return nil
+	})
+	return digests, err
+}
+
+// changedPageURLs returns the absolute URLs of pages that are new or differ
+// between the before and after digests.
+func changedPageURLs(baseURL string, before, after map[string][sha256.Size]byte) []string {
+	var urls []string
+	for rel, sum := range after {
+		if old, ok := before[rel]; ok && old == sum {
+			continue
+		}
+		switch {
+		case rel == "index.html":
+			urls = append(urls, baseURL+"/")
+		case strings.HasSuffix(rel, "/index.html"):
+			urls = append(urls, baseURL+"/"+strings.TrimSuffix(rel, "index.html"))
+		default:
+			urls = append(urls, baseURL+"/"+rel)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// renderIndexNowKey writes the <key>.txt file search engines fetch to verify
+// that a submission comes from the site owner.
+func renderIndexNowKey(cfg config) error {
+	target := filepath.Join(cfg.outputDir, cfg.indexNowKey+".txt")
+	if err := cfg.out.writeIfChanged(target, []byte(cfg.indexNowKey)); err != nil {
+		return fmt.Errorf("write indexnow key: %w", err)
+	}
+	return nil
+}
+
+// pingIndexNow submits urls to the IndexNow endpoint, retrying once. With
+// -indexnowDryRun the payload is printed instead.
+func pingIndexNow(ctx context.Context, cfg config, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	if len(urls) > indexNowMaxURLs {
+		urls = urls[:indexNowMaxURLs]
+	}
+	u, err := url.Parse(cfg.baseURL)
+	if err != nil {
+		return err
+	}
+	body, err := json.MarshalIndent(indexNowPayload{
+		Host:        u.Host,
+		Key:         cfg.indexNowKey,
+		KeyLocation: cfg.baseURL + "/" + cfg.indexNowKey + ".txt",
+		URLList:     urls,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode indexnow payload: %w", err)
+	}
+	if cfg.indexNowDryRun {
+		fmt.Printf("POST %s\n%s\n", cfg.indexNowEndpoint, body)
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = postJSON(ctx, cfg.indexNowEndpoint, body)
+		if err == nil || attempt == indexNowAttempts {
+			break
+		}
+		cfg.logger.Warn(fmt.Sprintf("IndexNow 전송 실패(%d/%d): %v", attempt, indexNowAttempts, err))
+	}
+	if err != nil {
+		return fmt.Errorf("indexnow %s: %w", cfg.indexNowEndpoint, err)
+	}
+	cfg.logger.Info(fmt.Sprintf("IndexNow에 변경된 주소 %d개를 알렸습니다.", len(urls)))
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestChangedPageURLs(t *testing.T) {
+	before := map[string][sha256.Size]byte{
+		"index.html":       sha256.Sum256([]byte("home")),
+		"hello/index.html": sha256.Sum256([]byte("hello")),
+		"404.html":         sha256.Sum256([]byte("missing")),
+	}
+	after := map[string][sha256.Size]byte{
+		"index.html":        sha256.Sum256([]byte("home, updated")),
+		"hello/index.html":  sha256.Sum256([]byte("hello")),
+		"404.html":          sha256.Sum256([]byte("missing, updated")),
+		"second/index.html": sha256.Sum256([]byte("second")),
+	}
+	got := changedPageURLs("https://x.dev/blog", before, after)
+	want := []string{"https://x.dev/blog/", "https://x.dev/blog/404.html", "https://x.dev/blog/second/"}
+	if !slices.Equal(got, want) {
+		t.Errorf("changedPageURLs = %v, want %v", got, want)
+	}
+}
+
+func TestIndexNowSubmitsChangedPages(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []indexNowPayload
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var p indexNowPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		payloads = append(payloads, p)
+	}))
+	t.Cleanup(srv.Close)
+	take := func() []indexNowPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		got := payloads
+		payloads = nil
+		return got
+	}
+
+	cfg := testConfig(t)
+	cfg.baseURL = "https://x.dev/blog"
+	cfg.indexNow = true
+	cfg.indexNowKey = "0123abcd"
+	cfg.indexNowEndpoint = srv.URL
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	writeContent(t, cfg, "second.md", "---\ntitle: Second\ndate: 2024-05-04\n---\nbody\n")
+	buildSite(t, cfg)
+
+	if got := readOutput(t, cfg, "0123abcd.txt"); got != "0123abcd" {
+		t.Errorf("key file = %q, want the key", got)
+	}
+	got := take()
+	if len(got) != 1 {
+		t.Fatalf("first build sent %d submissions, want 1", len(got))
+	}
+	first := got[0]
+	if first.Host != "x.dev" || first.Key != "0123abcd" || first.KeyLocation != "https://x.dev/blog/0123abcd.txt" {
+		t.Errorf("first submission = %+v", first)
+	}
+	for _, u := range []string{"https://x.dev/blog/", "https://x.dev/blog/hello/", "https://x.dev/blog/second/"} {
+		if !slices.Contains(first.URLList, u) {
+			t.Errorf("first submission does not list %s: %v", u, first.URLList)
+		}
+	}
+
+	buildSite(t, cfg)
+	if got := take(); len(got) > 0 {
+		t.Errorf("unchanged build sent %+v", got)
+	}
+
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nnew body\n")
+	buildSite(t, cfg)
+	got = take()
+	if len(got) != 1 {
+		t.Fatalf("build after an edit sent %d submissions, want 1", len(got))
+	}
+	if urls := got[0].URLList; !slices.Contains(urls, "https://x.dev/blog/hello/") || slices.Contains(urls, "https://x.dev/blog/second/") {
+		t.Errorf("urlList = %v, want hello/ and not second/", urls)
+	}
+}
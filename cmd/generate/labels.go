@@ -0,0 +1,129 @@
+package main
+
+import "fmt"
+
+// uiLabels holds the interface text of the templates and generated page
+// titles per base language. Templates read it with {{ label "key" args }}.
+// Sites in a language without a table get English.
+var uiLabels = map[string]map[string]string{
+	"ko": {
+		"home":              "홈",
+		"tags":              "태그",
+		"firstPage":         "첫 페이지",
+		"lastPage":          "마지막 페이지",
+		"prevPage":          "← 이전",
+		"nextPage":          "다음 →",
+		"pageTitle":         "%s (%d/%d쪽)",
+		"tagsPrefix":        "태그:",
+		"noPosts":           "아직 게시물이 없습니다.",
+		"noPostsPrompt":     "아직 게시물이 없습니다. 오늘 한 일을 적어보세요.",
+		"allPostsLink":      "전체 글 %d개 보기 →",
+		"postCount":         "%d개의 글이 있습니다.",
+		"links":             "링크",
+		"backHome":          "⟵ 홈으로",
+		"viewMarkdown":      "마크다운으로 보기",
+		"viewText":          "텍스트로 보기",
+		"editOnGitHub":      "GitHub에서 수정하기",
+		"seriesNav":         "시리즈:",
+		"prevPost":          "← 이전 글: %s",
+		"nextPost":          "다음 글: %s →",
+		"comments":          "댓글",
+		"seriesIndex":       "시리즈 모음",
+		"seriesIntro":       "여러 편으로 이어지는 글을 모아 보세요.",
+		"seriesMeta":        "(%d편 · %s 업데이트)",
+		"noSeries":          "아직 시리즈가 없습니다.",
+		"tagTitle":          "태그: %s",
+		"tagIndex":          "태그 모음",
+		"tagsIntro":         "관심 있는 주제로 글을 찾아보세요.",
+		"noTags":            "아직 태그가 없습니다.",
+		"opmlLink":          "모든 피드를 OPML로 구독하기",
+		"noTagPosts":        "이 태그에 해당하는 글이 없습니다.",
+		"allTags":           "← 전체 태그 보기",
+		"year":              "%d년",
+		"yearTitle":         "%d년의 글",
+		"yearAll":           "%d년 전체",
+		"archive":           "전체 글 목록",
+		"search":            "검색",
+		"searchPlaceholder": "검색어를 입력하세요",
+		"searchTerms":       "검색어",
+		"notFound":          "페이지를 찾을 수 없습니다",
+		"redirectBefore":    "",
+		"redirectAfter":     "(으)로 이동합니다.",
+		"readOnBlog":        "블로그에서 읽기 →",
+		"tagFeed":           "%s 태그가 붙은 글",
+		"authorFeed":        "%s님이 쓴 글",
+	},
+	"en": {
+		"home":              "Home",
+		"tags":              "Tags",
+		"firstPage":         "First page",
+		"lastPage":          "Last page",
+		"prevPage":          "← Previous",
+		"nextPage":          "Next →",
+		"pageTitle":         "%s (page %d of %d)",
+		"tagsPrefix":        "Tags:",
+		"noPosts":           "No posts yet.",
+		"noPostsPrompt":     "No posts yet. Write about what you did today.",
+		"allPostsLink":      "See all %d posts →",
+		"postCount":         "%d posts.",
+		"postCount.one":     "%d post.",
+		"links":             "Links",
+		"backHome":          "⟵ Home",
+		"viewMarkdown":      "View as Markdown",
+		"viewText":          "View as text",
+		"editOnGitHub":      "Edit on GitHub",
+		"seriesNav":         "series:",
+		"prevPost":          "← Previous: %s",
+		"nextPost":          "Next: %s →",
+		"comments":          "Comments",
+		"seriesIndex":       "Series",
+		"seriesIntro":       "Posts that continue over several parts.",
+		"seriesMeta":        "(%d parts · updated %s)",
+		"seriesMeta.one":    "(%d part · updated %s)",
+		"noSeries":          "No series yet.",
+		"tagTitle":          "Tag: %s",
+		"tagIndex":          "Tags",
+		"tagsIntro":         "Find posts by topic.",
+		"noTags":            "No tags yet.",
+		"opmlLink":          "Subscribe to every feed (OPML)",
+		"noTagPosts":        "No posts have this tag.",
+		"allTags":           "← All tags",
+		"year":              "%d",
+		"yearTitle":         "Posts from %d",
+		"yearAll":           "All of %d",
+		"archive":           "All posts",
+		"search":            "Search",
+		"searchPlaceholder": "Search posts",
+		"searchTerms":       "Search terms",
+		"notFound":          "Page not found",
+		"redirectBefore":    "Redirecting to ",
+		"redirectAfter":     ".",
+		"readOnBlog":        "Read on the blog →",
+		"tagFeed":           "Posts tagged %s",
+		"authorFeed":        "Posts by %s",
+	},
+}
+
+// uiLabel returns the interface text key in lang, formatted with args. When
+// the first argument is 1 a "<key>.one" entry is preferred. An unknown key
+// is returned as is, so a typo shows up on the page instead of failing the
+// build.
+func uiLabel(lang, key string, args ...any) string {
+	labels, ok := uiLabels[baseLang(lang)]
+	if !ok {
+		labels = uiLabels["en"]
+	}
+	format, ok := labels[key]
+	if !ok {
+		return key
+	}
+	if len(args) > 0 && args[0] == 1 {
+		if one, ok := labels[key+".one"]; ok {
+			format = one
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestEnglishSiteHasNoKoreanText(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.language = "en"
+	cfg.title = "Thumbgo Notes"
+	cfg.description = "Notes on operations"
+	cfg.pageSize = 2
+	cfg.searchIndex = true
+	cfg.tagFeeds = true
+	cfg.authorFeeds = true
+	cfg.api = true
+	for i := 1; i <= 3; i++ {
+		writeContent(t, cfg, fmt.Sprintf("part-%d.md", i), fmt.Sprintf(
+			"---\ntitle: Part %d\ndate: 2024-05-0%d\ntags: [go, ops]\nauthor: Ann\nseries: Basics\nseriesPart: %d\n---\nSee [the docs](https://go.dev/doc/).\n", i, i, i))
+	}
+	writeContent(t, cfg, "moved.md", "---\ntitle: Moved\ndate: 2024-04-01\nredirect_to: https://new.example/moved/\naliases: [/old/]\n---\nbody\n")
+	buildSite(t, cfg)
+	for rel, want := range map[string]string{
+		"tags/go/index.html": "3 posts.",
+		"tags/index.html":    "Find posts by topic.",
+		"2024/index.html":    "Posts from 2024",
+		"page/2/index.html":  "Thumbgo Notes (page 2 of 2)",
+		"old/index.html":     "Redirecting to ",
+	} {
+		if page := readOutput(t, cfg, rel); !strings.Contains(page, want) {
+			t.Errorf("%s does not contain %q", rel, want)
+		}
+	}
+
+	err := filepath.WalkDir(cfg.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		for i, r := range string(data) {
+			if unicode.Is(unicode.Hangul, r) {
+				rel, _ := filepath.Rel(cfg.outputDir, p)
+				start := max(0, i-40)
+				t.Errorf("%s has Korean text near %q", rel, string(data[start:min(len(data), i+40)]))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUILabel(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		args []any
+		want string
+	}{
+		{"ko", "postCount", []any{3}, "3개의 글이 있습니다."},
+		{"ko-KR", "postCount", []any{1}, "1개의 글이 있습니다."},
+		{"en", "postCount", []any{3}, "3 posts."},
+		{"en-US", "postCount", []any{1}, "1 post."},
+		{"fr", "tagTitle", []any{"go"}, "Tag: go"},
+		{"en", "noSuchLabel", nil, "noSuchLabel"},
+	}
+	for _, tt := range tests {
+		if got := uiLabel(tt.lang, tt.key, tt.args...); got != tt.want {
+			t.Errorf("uiLabel(%q, %q, %v) = %q, want %q", tt.lang, tt.key, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLabelTablesHaveTheSameKeys(t *testing.T) {
+	for key := range uiLabels["ko"] {
+		if _, ok := uiLabels["en"][key]; !ok {
+			t.Errorf("en has no label %q", key)
+		}
+	}
+	for key := range uiLabels["en"] {
+		if _, ok := uiLabels["ko"][key]; !ok && filepath.Ext(key) != ".one" {
+			t.Errorf("ko has no label %q", key)
+		}
+	}
+}
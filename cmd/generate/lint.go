@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// lintRule is a soft content check. Check returns one message per finding;
+// rules never stop the build on their own, -lintFail (and -strictA11y for
+// image-alt) decides that.
+type lintRule struct {
+	Name  string
+	Check func(cfg config, p post) []string
+}
+
+type lintFinding struct {
+	Path    string
+	Rule    string
+	Message string
+}
+
+const maxTitleLength = 70
+
+// lintRules is the registry of available rules. New rules only need to be
+// appended here; each can be switched off by name with -lintDisable.
+var lintRules = []lintRule{
+	{Name: "missing-summary", Check: lintMissingSummary},
+	{Name: "no-tags", Check: lintNoTags},
+	{Name: "long-title", Check: lintLongTitle},
+	{Name: "h1-heading", Check: lintH1Heading},
+	{Name: "image-alt", Check: lintImageAlt},
+}
+
+func lintPosts(cfg config, posts []post) []lintFinding {
+	off := make(map[string]bool, len(cfg.lintDisable))
+	for _, name := range cfg.lintDisable {
+		off[name] = true
+	}
+
+	var findings []lintFinding
+	for _, p := range posts {
+		for _, rule := range lintRules {
+			if off[rule.Name] {
+				continue
+			}
+			for _, msg := range rule.Check(cfg, p) {
+				findings = append(findings, lintFinding{Path: p.SourcePath, Rule: rule.Name, Message: msg})
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+// reportLint prints findings grouped under their source file.
+func reportLint(logger *slog.Logger, findings []lintFinding) {
+	var b strings.Builder
+	last := ""
+	for _, f := range findings {
+		if f.Path != last {
+			fmt.Fprintf(&b, "%s\n", f.Path)
+			last = f.Path
+		}
+		fmt.Fprintf(&b, "  [%s] %s\n", f.Rule, f.Message)
+	}
+	if b.Len() > 0 {
+		logger.Info(fmt.Sprintf("콘텐츠 점검 결과 %d건:\n%s", len(findings), strings.TrimRight(b.String(), "\n")))
+	}
+}
+
+func lintMissingSummary(_ config, p post) []string {
+	if strings.TrimSpace(p.Summary) == "" && strings.TrimSpace(p.Description) == "" {
+		return []string{"no summary or description"}
+	}
+	return nil
+}
+
+func lintNoTags(_ config, p post) []string {
+	if len(p.Tags) == 0 {
+		return []string{"no tags"}
+	}
+	return nil
+}
+
+func lintLongTitle(_ config, p post) []string {
+	if n := utf8.RuneCountInString(p.Title); n > maxTitleLength {
+		return []string{fmt.Sprintf("title is %d characters (max %d)", n, maxTitleLength)}
+	}
+	return nil
+}
+
+func lintH1Heading(_ config, p post) []string {
+	var msgs []string
+	walkPostAST(p, func(n ast.Node) ast.WalkStatus {
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue
+		}
+		if h.Level == 1 {
+			msgs = append(msgs, fmt.Sprintf("first heading %q is an H1; the page template already renders the title as H1", inlineText(h, p.source, plainTextOptions{})))
+		}
+		return ast.WalkStop
+	})
+	return msgs
+}
+
+// decorativeImageTitle marks an image with empty alt text as decorative:
+// ![](divider.png "decorative").
+const decorativeImageTitle = "decorative"
+
+// lintImageAlt reports images whose alt text is missing or just repeats
+// the file name. Decorative images are excused by the title marker or by
+// matching a -a11yAllow pattern.
+func lintImageAlt(cfg config, p post) []string {
+	var msgs []string
+	walkPostAST(p, func(n ast.Node) ast.WalkStatus {
+		img, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue
+		}
+		dest := string(img.Destination)
+		alt := strings.TrimSpace(inlineText(img, p.source, plainTextOptions{}))
+		file := path.Base(strings.SplitN(dest, "?", 2)[0])
+		switch {
+		case alt == "" && (string(img.Title) == decorativeImageTitle || a11yAllowed(cfg.a11yAllow, dest)):
+			// Decorative.
+		case alt == "":
+			msgs = append(msgs, fmt.Sprintf("line %d: image %s has no alt text", sourceLine(p, n), dest))
+		case strings.EqualFold(alt, file) || strings.EqualFold(alt, strings.TrimSuffix(file, path.Ext(file))):
+			msgs = append(msgs, fmt.Sprintf("line %d: image %s has its file name as alt text", sourceLine(p, n), dest))
+		}
+		return ast.WalkSkipChildren
+	})
+	return msgs
+}
+
+// a11yAllowed reports whether an image destination, or its file name,
+// matches one of the path.Match patterns.
+func a11yAllowed(patterns []string, dest string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, dest); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(dest)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceLine approximates the line of n in p's source file from the first
+// line of its enclosing block. Shortcodes expanded over several lines make
+// later lines drift.
+func sourceLine(p post, n ast.Node) int {
+	for ; n != nil; n = n.Parent() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			return p.bodyLine + bytes.Count(p.source[:n.Lines().At(0).Start], []byte("\n")) + 1
+		}
+	}
+	return p.bodyLine + 1
+}
+
+// lintFailures returns the findings that fail the build: all of them with
+// -lintFail, otherwise the accessibility ones with -strictA11y.
+func lintFailures(cfg config, findings []lintFinding) []lintFinding {
+	if cfg.lintFail {
+		return findings
+	}
+	var out []lintFinding
+	if cfg.strictA11y {
+		for _, f := range findings {
+			if a11yRules[f.Rule] {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// walkPostAST visits the parsed markdown of p in document order.
+func walkPostAST(p post, fn func(n ast.Node) ast.WalkStatus) {
+	if p.doc == nil {
+		return
+	}
+	_ = ast.Walk(p.doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		return fn(n), nil
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	buildLockName = ".build.lock"
+	// staleLockAge is how old a lock must be before it is broken even when
+	// its process appears to be alive (e.g. the PID was reused).
+	staleLockAge = time.Hour
+	// lockGrace is how long an unreadable lock is trusted; only a lock that
+	// stays unreadable longer than this is treated as abandoned.
+	lockGrace = 10 * time.Second
+)
+
+// acquireBuildLock creates outputDir/.build.lock holding this process's PID
+// and start time, waiting up to wait for another build to finish. The
+// contents are written to a temporary file first and hard-linked into
+// place, so the lock never exists half-written. Locks left by a process
+// that no longer exists, or older than staleLockAge, are removed. The
+// returned func releases the lock.
+func acquireBuildLock(cfg config, wait time.Duration) (func(), error) {
+	target := filepath.Join(cfg.outputDir, buildLockName)
+	deadline := time.Now().Add(wait)
+	for {
+		err := createBuildLock(target)
+		if err == nil {
+			return func() { os.Remove(target) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		pid, started, ok := readBuildLock(target)
+		if lockAbandoned(target, pid, started, ok) {
+			cfg.logger.Warn(fmt.Sprintf("이전 빌드가 남긴 잠금 파일 %s을 제거합니다.", target))
+			if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("remove stale build lock: %w", err)
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			if !ok {
+				return nil, fmt.Errorf("build already in progress (unreadable lock %s)", target)
+			}
+			return nil, fmt.Errorf("build already in progress (pid %d since %s, lock %s)", pid, started.Format(time.RFC3339), target)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// createBuildLock atomically creates target with this process's lock
+// contents. It fails with fs.ErrExist when another build holds the lock.
+func createBuildLock(target string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), buildLockName+"-*")
+	if err != nil {
+		return fmt.Errorf("create build lock: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, werr := fmt.Fprintf(tmp, "%d %s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return fmt.Errorf("write build lock: %w", werr)
+	}
+	if err := os.Link(tmp.Name(), target); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("create build lock: %w", err)
+	}
+	return nil
+}
+
+// lockAbandoned reports whether the lock at target may be broken. A lock
+// that cannot be parsed is kept until it is older than lockGrace.
+func lockAbandoned(target string, pid int, started time.Time, ok bool) bool {
+	if !ok {
+		info, err := os.Stat(target)
+		return err == nil && time.Since(info.ModTime()) > lockGrace
+	}
+	return !processAlive(pid) || time.Since(started) > staleLockAge
+}
+
+// readBuildLock parses a lock file written by acquireBuildLock.
+func readBuildLock(target string) (int, time.Time, bool) {
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, time.Time{}, false
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	started, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return pid, started, true
+}
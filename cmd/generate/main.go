@@ -3,43 +3,219 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/xml"
+	"crypto/sha256"
 	"errors"
 	"flag"
 	"fmt"
+	stdhtml "html"
 	"html/template"
 	"io"
 	"io/fs"
-	"log"
+	"log/slog"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	texttemplate "text/template"
 	"time"
 	"unicode"
 
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 	"gopkg.in/yaml.v3"
 )
 
 type config struct {
-	contentDir  string
+	logger *slog.Logger
+
+	contentDirs []string
+	mounts      map[string]contentMount
+	extensions  []string
 	outputDir   string
+	// fileMode is the permission of generated files, from -fileMode.
+	fileMode fs.FileMode
+	// out records this run's writes; run sets it.
+	out         *buildOutput
 	templateDir string
 	assetDir    string
 	baseURL     string
+	language    string
+	location    *time.Location
+	now         time.Time
+	env         string
+	check       bool
+	strict      bool
+	// uniqueTitles warns about posts sharing a title.
+	uniqueTitles bool
+
+	plainText       bool
+	plainTextCode   bool
+	plainTextImages bool
+
+	referencedAssetsOnly bool
+	alwaysAssets         []string
+
+	lintDisable []string
+	lintFail    bool
+	strictA11y  bool
+	a11yCheck   bool
+	a11yAllow   []string
+
+	imageCDN string
+
+	searchIndex      bool
+	searchIndexLimit int
+
+	drafts      bool
+	draftBanner string
+	draftPrefix string
+
+	tagGraph bool
+
+	fenceRenderers fenceRenderers
+	fenceTimeout   time.Duration
+
+	notifyURL      string
+	notifyRequired bool
+
+	api        bool
+	exportJSON bool
+
+	exportMarkdown            bool
+	exportMarkdownFrontMatter bool
+
+	tagFeeds    bool
+	authorFeeds bool
+
+	cname       string
+	githubPages bool
+
+	indexLimit int
+	pageSize   int
+
+	tagPageSize int
+
+	redirectsFormat string
+	redirectsFile   string
+	headersFormat   string
+	headersFile     string
+
+	githubRepo   string
+	githubBranch string
+
+	feedDescriptionMode string
+	expired             bool
+	gitInfo             bool
+
+	comments commentsConfig
+
+	indexNow         bool
+	indexNowKey      string
+	indexNowEndpoint string
+	indexNowDryRun   bool
+
+	imageReport bool
+
+	fbAppID string
+
+	feedArchiveSize int
+
+	headerExtra template.HTML
+	footerExtra template.HTML
+
+	numberHeadings bool
+
+	manifest *webManifest
+
+	serviceWorker bool
+
+	analytics        analyticsConfig
+	analyticsSnippet template.HTML
+
+	csvHeader bool
+
+	jsonPages bool
+
+	csp      bool
+	cspExtra cspSources
+	// cspPolicy is computed in run once posts are loaded.
+	cspPolicy string
+
+	tagAliases tagAliases
+	// tagSlugs is filled in run once tags are grouped.
+	tagSlugs tagSlugs
+
+	sri            bool
+	assetIntegrity *assetIntegrity
+
+	preload []string
+
+	renderTimeout time.Duration
+
+	lockWait time.Duration
+
+	title       string
+	description string
+	copyright   string
+
+	topicNav bool
+
+	// authors is nil unless an authors data file exists.
+	authors map[string]*authorProfile
+
+	textPages bool
+
+	collectLinks bool
+
+	dateInPath string
+
+	data map[string]any
+
+	feedUpdateThreshold time.Duration
+	feedResurface       bool
+
+	siteExcerptLength int
+	siteExcerptHTML   bool
+	feedExcerptLength int
+
+	snippetDir string
+	snippets   map[string]*texttemplate.Template
 }
 
 type frontMatter struct {
-	Title       string    `yaml:"title"`
-	Date        time.Time `yaml:"date"`
-	Tags        []string  `yaml:"tags"`
-	Summary     string    `yaml:"summary"`
-	Description string    `yaml:"description"`
-	Draft       bool      `yaml:"draft"`
+	Title          string    `yaml:"title"`
+	Date           time.Time `yaml:"date"`
+	Tags           []string  `yaml:"tags"`
+	Summary        string    `yaml:"summary"`
+	Description    string    `yaml:"description"`
+	Draft          bool      `yaml:"draft"`
+	Unlisted       bool      `yaml:"unlisted,omitempty"`
+	Aliases        []string  `yaml:"aliases,omitempty"`
+	Thumbnail      string    `yaml:"thumbnail,omitempty"`
+	AllowEmpty     bool      `yaml:"allowEmpty,omitempty"`
+	ExpiryDate     time.Time `yaml:"expiryDate,omitempty"`
+	Comments       *bool     `yaml:"comments,omitempty"`
+	Lang           string    `yaml:"lang,omitempty"`
+	NumberHeadings *bool     `yaml:"numberHeadings,omitempty"`
+	Author         string    `yaml:"author,omitempty"`
+	Analytics      *bool     `yaml:"analytics,omitempty"`
+	Series         string    `yaml:"series,omitempty"`
+	SeriesPart     int       `yaml:"seriesPart,omitempty"`
+	Feed           *bool     `yaml:"feed,omitempty"`
+	LastMod        time.Time `yaml:"lastmod,omitempty"`
+	Outputs        []string  `yaml:"outputs,omitempty"`
+	RedirectTo     string    `yaml:"redirect_to,omitempty"`
+
+	// zoneless marks the date keys written without a zone.
+	zoneless map[string]bool
 }
 
 type post struct {
@@ -50,337 +226,1581 @@ type post struct {
 	Summary     string
 	Description string
 	Draft       bool
+	Unlisted    bool
+	Aliases     []string
+	// RedirectTo is the absolute URL a superseded post now lives at. Such
+	// posts render only as a redirect and are never listed.
+	RedirectTo string
+	Thumbnail  string
+	EditURL    string
+	ExpiryDate time.Time
+	// LastMod is the front matter lastmod; zero when unset.
+	LastMod  time.Time
+	Git      gitInfo
+	Comments postComments
+	Lang     string
+	Author   string
+	// AuthorProfile is the resolved entry of the authors data file, if any.
+	AuthorProfile *authorProfile
+	Analytics     bool
+	Series        string
+	SeriesPart    int
+	// Feed is false for posts kept out of every feed with `feed: false`.
+	Feed        bool
+	Headings    []heading
+	Nav         postNav
 	ContentHTML template.HTML
 	ContentRaw  []byte
-	SourcePath  string
+	PlainText   string
+	ReadingTime int
+	// SiteExcerpt is the listing excerpt configured by -siteExcerptLength
+	// and -siteExcerptHTML.
+	SiteExcerpt template.HTML
+	MarkdownURL string
+	TextURL     string
+	// Links lists the external links of the body with -collectLinks.
+	Links      []footLink
+	SourcePath string
+
+	doc ast.Node
+	// source is the markdown doc was parsed from: the body with shortcodes
+	// expanded.
+	source []byte
+	src    []byte
+	// outputs holds the output formats the post is rendered to.
+	outputs map[string]bool
+	// bodyLine is the number of source lines before the body.
+	bodyLine int
+	// repo is the GitHub repository of the post's content mount.
+	repo string
 }
 
 type templateBundle struct {
-	layout *template.Template
-	index  *template.Template
-	post   *template.Template
-	tags   *template.Template
-	tag    *template.Template
+	layout  *template.Template
+	index   *template.Template
+	post    *template.Template
+	tags    *template.Template
+	tag     *template.Template
+	search  *template.Template
+	year    *template.Template
+	month   *template.Template
+	archive *template.Template
+	series  *template.Template
+	author  *template.Template
+
+	// notFound is templates/404.html and sectionNotFound the
+	// templates/<section>-404.html pages, keyed by section; both optional.
+	notFound        *template.Template
+	sectionNotFound map[string]*template.Template
+}
+
+// site holds the data shared by every page, exposed to templates as .Site.
+type site struct {
+	Title       string
+	Description string
+	Copyright   string
+	// GithubURL is the profile of the -repo owner, or "" without a repo.
+	GithubURL      string
+	Generator      string
+	Env            string
+	Language       string
+	Locale         string
+	FBAppID        string
+	HeaderExtra    template.HTML
+	FooterExtra    template.HTML
+	IsProduction   bool
+	NoIndex        bool
+	SearchIndexURL string
+	ArchiveURL     string
+	Comments       *commentsConfig
+	ManifestURL    string
+	ThemeColor     string
+	Icons          []manifestIcon
+
+	ServiceWorkerScript template.HTML
+	Analytics           *analyticsConfig
+	AnalyticsSnippet    template.HTML
+
+	ContentSecurityPolicy string
+	ResourceHints         []resourceHint
+	Feeds                 []feedLink
+	Data                  map[string]any
+}
+
+func newSite(cfg config) site {
+	s := site{
+		Title:        cfg.title,
+		Description:  cfg.description,
+		Copyright:    cfg.copyright,
+		Generator:    generatorName + " " + generatorVersion(),
+		Env:          cfg.env,
+		Language:     cfg.language,
+		Locale:       ogLocale(cfg.language),
+		FBAppID:      cfg.fbAppID,
+		HeaderExtra:  cfg.headerExtra,
+		FooterExtra:  cfg.footerExtra,
+		IsProduction: cfg.env == envProduction,
+		NoIndex:      cfg.env != envProduction,
+		ArchiveURL:   archiveURL,
+
+		ServiceWorkerScript: serviceWorkerScript(cfg),
+
+		ContentSecurityPolicy: cfg.cspPolicy,
+		ResourceHints:         preloadHints(cfg.preload),
+		Feeds:                 siteFeeds(cfg),
+		Data:                  cfg.data,
+	}
+	if owner, _, _ := strings.Cut(cfg.githubRepo, "/"); owner != "" {
+		s.GithubURL = "https://github.com/" + owner
+	}
+	if cfg.searchIndex {
+		s.SearchIndexURL = searchIndexURL
+	}
+	if cfg.comments.Provider != "" {
+		comments := cfg.comments
+		s.Comments = &comments
+	}
+	if analyticsEnabled(cfg) {
+		analytics := cfg.analytics
+		s.Analytics = &analytics
+		s.AnalyticsSnippet = cfg.analyticsSnippet
+	}
+	if cfg.manifest != nil {
+		s.ManifestURL = manifestURL
+		s.ThemeColor = cfg.manifest.ThemeColor
+		s.Icons = cfg.manifest.Icons
+	}
+	return s
+}
+
+const (
+	commentsUtterances = "utterances"
+	commentsGiscus     = "giscus"
+)
+
+// commentsConfig is the comment widget setup exposed as .Site.Comments. It
+// is nil in templates when no provider is configured.
+type commentsConfig struct {
+	Provider   string
+	Repo       string
+	RepoID     string
+	Category   string
+	CategoryID string
+	Mapping    string
+	Theme      string
+}
+
+type postComments struct {
+	Enabled bool
+}
+
+// commentsEnabled reports whether a post shows comments. Drafts and unlisted
+// posts default to off; `comments:` in front matter overrides either way.
+func commentsEnabled(cfg config, fm frontMatter) bool {
+	if cfg.comments.Provider == "" {
+		return false
+	}
+	if fm.Comments != nil {
+		return *fm.Comments
+	}
+	return !fm.Draft && !fm.Unlisted
 }
 
 type tagGroup struct {
 	Name  string
 	Slug  string
 	Posts []post
+	// key is the tagKey of Name; see tagSlugs.
+	key string
+}
+
+const githubRepo = "yoonhyunwoo/blog"
+
+const defaultBaseURL = "https://example.com"
+
+const (
+	envProduction = "production"
+	envPreview    = "preview"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		var sub func([]string) error
+		switch os.Args[1] {
+		case "new":
+			sub = runNew
+		case "deploy":
+			sub = runDeploy
+		case "newsletter":
+			sub = runNewsletter
+		}
+		if sub != nil {
+			if err := sub(os.Args[2:]); err != nil {
+				fatal(slog.Default(), "generate: %v", err)
+			}
+			return
+		}
+	}
+
+	cfg := config{fenceRenderers: fenceRenderers{}, logger: slog.Default()}
+	contentDirs := flag.String("content", "content", "Comma-separated list of markdown content directories, each optionally dir=prefix or dir=prefix@owner/repo to publish under /prefix/ and edit in another repo")
+	extensions := flag.String("extensions", ".md,.markdown", "Comma-separated content file extensions (matched case-insensitively)")
+	flag.StringVar(&cfg.templateDir, "templates", "templates", "HTML template directory")
+	flag.StringVar(&cfg.assetDir, "assets", "assets", "Static asset directory")
+	flag.StringVar(&cfg.outputDir, "out", "public", "Build output directory")
+	flag.StringVar(&cfg.baseURL, "baseURL", "", "Base URL used for absolute links in RSS (e.g. https://thumbgo.dev)")
+	flag.StringVar(&cfg.language, "language", "ko", "Site language code used for feeds and localized archive names")
+	timezone := flag.String("timezone", "UTC", "IANA time zone for zone-less front matter dates, displayed dates, archives and feeds (e.g. Asia/Seoul)")
+	flag.StringVar(&cfg.env, "env", envProduction, "Build environment: production or preview (preview includes drafts and adds noindex)")
+	flag.BoolVar(&cfg.check, "check", false, "Validate templates and content without writing any output")
+	flag.BoolVar(&cfg.strict, "strict", false, "Treat warnings (such as a missing -baseURL) as errors")
+	flag.BoolVar(&cfg.uniqueTitles, "uniqueTitles", false, "Warn when two non-draft posts share a title (an error with -strict)")
+	flag.BoolVar(&cfg.plainText, "plainText", false, "Also write each post's reading-order plain text to <slug>/content.txt")
+	flag.BoolVar(&cfg.plainTextCode, "plainTextCode", false, "Include code blocks in post plain text")
+	flag.BoolVar(&cfg.plainTextImages, "plainTextImages", false, "Include image alt text in post plain text")
+	flag.BoolVar(&cfg.referencedAssetsOnly, "referencedAssetsOnly", false, "Copy only assets referenced by generated pages")
+	alwaysAssets := flag.String("alwaysAssets", "", "Comma-separated asset globs copied even when unreferenced (e.g. fonts/*)")
+	lintDisable := flag.String("lintDisable", "", "Comma-separated lint rules to skip (missing-summary, no-tags, long-title, h1-heading, image-alt)")
+	flag.BoolVar(&cfg.lintFail, "lintFail", false, "Exit with an error when content lint reports findings")
+	flag.BoolVar(&cfg.strictA11y, "strictA11y", false, "Exit with an error on accessibility findings (image-alt and the -a11yCheck rules)")
+	flag.BoolVar(&cfg.a11yCheck, "a11yCheck", false, "After the build, check generated pages for skipped heading levels, duplicate ids, vague link text and a missing html lang")
+	a11yAllow := flag.String("a11yAllow", "", "Comma-separated image path patterns treated as decorative, e.g. *.svg,/assets/dividers/*")
+	flag.StringVar(&cfg.imageCDN, "imageCDN", "", "Base URL that local image paths in post content are rewritten to (e.g. https://cdn.thumbgo.kr)")
+	flag.BoolVar(&cfg.searchIndex, "searchIndex", false, "Write search/index.json for client-side search")
+	flag.IntVar(&cfg.searchIndexLimit, "searchIndexLimit", 5000, "Maximum characters of body text per post in the search index (0 for no limit)")
+	flag.BoolVar(&cfg.drafts, "drafts", false, "Include draft posts for previewing")
+	flag.StringVar(&cfg.draftPrefix, "draftPrefix", "_", "Treat content files whose name starts with this prefix as drafts (empty disables)")
+	flag.StringVar(&cfg.draftBanner, "draftBanner", "DRAFT — do not share", "Banner text shown on draft pages when built with -drafts")
+	flag.BoolVar(&cfg.tagGraph, "tagGraph", false, "Write tag-graph.json describing tag co-occurrence")
+	flag.Var(cfg.fenceRenderers, "fenceRenderer", "Render fenced blocks of a language to inline SVG with a command, as lang=command (repeatable, e.g. dot=dot -Tsvg)")
+	flag.DurationVar(&cfg.fenceTimeout, "fenceTimeout", 10*time.Second, "Timeout for each -fenceRenderer command")
+	flag.StringVar(&cfg.notifyURL, "notifyURL", "", "URL to POST a JSON build summary to after a successful build")
+	flag.BoolVar(&cfg.notifyRequired, "notifyRequired", false, "Fail the build when the -notifyURL request fails")
+	flag.BoolVar(&cfg.api, "api", false, "Write api/posts.json and api/tags.json")
+	flag.BoolVar(&cfg.exportJSON, "exportJSON", false, "Also write each post's front matter, HTML and markdown to <slug>/index.json")
+	flag.BoolVar(&cfg.exportMarkdown, "exportMarkdown", false, "Also write each post's markdown source to <slug>/index.md")
+	flag.BoolVar(&cfg.exportMarkdownFrontMatter, "exportMarkdownFrontMatter", false, "Keep the front matter in files written by -exportMarkdown")
+	flag.BoolVar(&cfg.tagFeeds, "tagFeeds", false, "Write an RSS feed per tag under feeds/tags/")
+	flag.BoolVar(&cfg.authorFeeds, "authorFeeds", false, "Write an RSS feed per front matter author under feeds/authors/")
+	flag.StringVar(&cfg.cname, "cname", "", "Custom domain for the GitHub Pages CNAME file (defaults to the -baseURL host, \"off\" to disable)")
+	flag.BoolVar(&cfg.githubPages, "githubPages", false, "Build for GitHub Pages: write .nojekyll (implied for github.io base URLs)")
+	flag.IntVar(&cfg.indexLimit, "indexLimit", 0, "Maximum number of posts listed on the homepage when -pageSize is 0 (0 lists all)")
+	flag.IntVar(&cfg.pageSize, "pageSize", 10, "Posts per homepage page; later pages go to /page/<n>/ (0 disables pagination)")
+	flag.IntVar(&cfg.tagPageSize, "tagPageSize", -1, "Posts per tag page, paginated under /tags/<slug>/page/<n>/ (-1 uses -pageSize, 0 disables pagination)")
+	flag.StringVar(&cfg.redirectsFormat, "redirectsFormat", "", "Emit alias redirects as a host redirect file instead of meta-refresh pages (netlify)")
+	flag.StringVar(&cfg.redirectsFile, "redirects", "redirects.yaml", "Optional YAML list of extra redirect rules (from, to, status) for -redirectsFormat")
+	flag.StringVar(&cfg.headersFormat, "headersFormat", "", "Write a host _headers file with cache policies (netlify, also read by Cloudflare Pages)")
+	flag.StringVar(&cfg.headersFile, "headers", "headers.yaml", "Optional YAML map of path patterns to extra or overriding headers for -headersFormat")
+	flag.StringVar(&cfg.githubRepo, "repo", githubRepo, "GitHub owner/name used for edit links and comments (empty disables edit links)")
+	flag.StringVar(&cfg.githubBranch, "branch", "main", "Branch that edit links point at")
+	flag.StringVar(&cfg.feedDescriptionMode, "feedDescriptionMode", feedDescriptionPlain, "RSS item descriptions as plain text (plain) or summary markdown rendered to HTML in CDATA (html)")
+	flag.BoolVar(&cfg.expired, "expired", false, "Keep posts whose expiryDate has passed")
+	flag.BoolVar(&cfg.gitInfo, "gitInfo", false, "Attach first/last commit metadata from git to each post")
+	flag.StringVar(&cfg.comments.Provider, "comments", commentsUtterances, "Comment provider: utterances, giscus or empty to disable comments")
+	flag.StringVar(&cfg.comments.Repo, "commentsRepo", "", "GitHub owner/name holding comments (defaults to -repo)")
+	flag.StringVar(&cfg.comments.RepoID, "commentsRepoID", "", "giscus repository ID")
+	flag.StringVar(&cfg.comments.Category, "commentsCategory", "", "giscus discussion category name")
+	flag.StringVar(&cfg.comments.CategoryID, "commentsCategoryID", "", "giscus discussion category ID")
+	flag.StringVar(&cfg.comments.Mapping, "commentsMapping", "pathname", "How pages map to issues or discussions (pathname, url, title, og:title)")
+	flag.StringVar(&cfg.comments.Theme, "commentsTheme", "github-light", "Comment widget theme")
+	flag.BoolVar(&cfg.indexNow, "indexnow", false, "Submit pages changed by the build to IndexNow (requires -indexnowKey and -baseURL)")
+	flag.StringVar(&cfg.indexNowKey, "indexnowKey", "", "IndexNow key; also written to <key>.txt at the output root")
+	flag.StringVar(&cfg.indexNowEndpoint, "indexnowEndpoint", indexNowEndpoint, "IndexNow submission endpoint")
+	flag.BoolVar(&cfg.indexNowDryRun, "indexnowDryRun", false, "Print the IndexNow payload instead of sending it")
+	flag.BoolVar(&cfg.imageReport, "imageReport", false, "Write images.json listing every image used by posts, with sizes of local files")
+	flag.StringVar(&cfg.fbAppID, "fbAppID", "", "Facebook app ID emitted as fb:app_id on every page")
+	flag.IntVar(&cfg.feedArchiveSize, "feedArchive", 0, "Also write every post to RFC 5005 archive feeds of this many items (feeds/rss-page-<n>.xml, oldest first; 0 disables)")
+	headerExtra := flag.String("headerExtra", "", "HTML snippet injected into every page header (default <templates>/headerExtra.html if present)")
+	footerExtra := flag.String("footerExtra", "", "HTML snippet injected into every page footer (default <templates>/footerExtra.html if present)")
+	flag.BoolVar(&cfg.numberHeadings, "numberHeadings", false, "Prefix h2-h4 headings with hierarchical numbers (1, 1.1, ...); posts can opt out with numberHeadings: false")
+	manifestFile := flag.String("manifest", "manifest.yaml", "Optional YAML web app manifest config; when present, site.webmanifest is written")
+	flag.BoolVar(&cfg.serviceWorker, "serviceWorker", false, "Write sw.js for offline reading and expose its registration script as .Site.ServiceWorkerScript")
+	flag.StringVar(&cfg.analytics.Provider, "analytics", "", "Analytics provider: plausible, goatcounter or empty to disable")
+	flag.StringVar(&cfg.analytics.Domain, "analyticsDomain", "", "Plausible site domain or GoatCounter site code")
+	flag.StringVar(&cfg.analytics.ScriptURL, "analyticsScript", "", "Override the provider's script URL (e.g. a self-hosted or proxied copy)")
+	flag.BoolVar(&cfg.csvHeader, "csvHeader", true, "Treat the first row of {{< csv >}} includes as the table header (override per include with header=false)")
+	flag.BoolVar(&cfg.jsonPages, "jsonPages", false, "Also write each post as JSON (metadata, canonical URL and HTML) to <slug>/index.json")
+	flag.BoolVar(&cfg.csp, "csp", false, "Compute a Content-Security-Policy for .Site.ContentSecurityPolicy and the _headers file")
+	cfg.cspExtra = cspSources{}
+	flag.Var(cfg.cspExtra, "cspExtra", "Extra CSP sources as \"directive source...\" (repeatable)")
+	cfg.tagAliases = tagAliases{}
+	flag.Var(cfg.tagAliases, "tagAlias", "Fold a tag into another as alias=canonical, e.g. k8s=kubernetes (repeatable)")
+	flag.BoolVar(&cfg.sri, "sri", false, "Expose sha384 subresource integrity values for local CSS and JS via the integrity template function")
+	preload := flag.String("preload", "", "Comma-separated local paths to preload on every page (e.g. /assets/style.css,/assets/fonts/body.woff2)")
+	flag.DurationVar(&cfg.renderTimeout, "renderTimeout", 30*time.Second, "Give up on a content file whose markdown takes longer than this to render (0 disables)")
+	flag.DurationVar(&cfg.lockWait, "lockWait", 10*time.Second, "How long to wait for another build writing the same output directory before giving up")
+	flag.StringVar(&cfg.title, "title", defaultTitle, "Site title used in page headers, feeds and OPML")
+	flag.StringVar(&cfg.description, "description", defaultDescription, "Site description used as the header tagline and the feed description")
+	flag.StringVar(&cfg.copyright, "copyright", "thumbgo", "Copyright holder shown in the page footer")
+	flag.BoolVar(&cfg.topicNav, "topicNav", false, "Compute older/newer links within each tag and series for post pages (.TagNav, .SeriesNav)")
+	flag.BoolVar(&cfg.textPages, "textPages", false, "Also write each post as wrapped plain text with verbatim code to <slug>/index.txt")
+	flag.BoolVar(&cfg.collectLinks, "collectLinks", false, "List each post's external links with their URLs after the body (.Post.Links)")
+	fileMode := flag.String("fileMode", "0644", "Octal permission for generated files")
+	authorsFile := flag.String("authors", filepath.Join("data", "authors.yaml"), "Optional YAML file of author profiles; when present, author: in front matter must be one of its keys")
+	flag.StringVar(&cfg.dateInPath, "dateInPath", "", "Prefix post paths with their date: year (/2024/slug/), month (/2024/05/slug/) or empty")
+	dataDir := flag.String("data", "data", "Directory of YAML and JSON files exposed to templates as .Site.Data")
+	flag.DurationVar(&cfg.feedUpdateThreshold, "feedUpdateThreshold", 24*time.Hour, "Mark a feed item updated (atom:updated) only when its lastmod is at least this long after its date")
+	flag.BoolVar(&cfg.feedResurface, "feedResurface", false, "Order the latest feeds by update time so significantly updated posts resurface (archive pages keep date order)")
+	flag.StringVar(&cfg.snippetDir, "snippets", "snippets", "Directory of reusable markdown snippets for {{< snippet \"name\" >}}")
+	flag.IntVar(&cfg.siteExcerptLength, "siteExcerptLength", 0, "Runes of body text used as .SiteExcerpt for posts without a summary (0 leaves it empty)")
+	flag.BoolVar(&cfg.siteExcerptHTML, "siteExcerptHTML", false, "Render .SiteExcerpt summaries as HTML instead of stripping them to plain text")
+	flag.IntVar(&cfg.feedExcerptLength, "feedExcerptLength", 200, "Runes of body text used as the feed description for posts without a summary (0 leaves it empty)")
+	flag.Parse()
+	if err := applyEnvFlags(flag.CommandLine, envFlags); err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+
+	switch cfg.redirectsFormat {
+	case "", hostNetlify:
+	default:
+		fatal(cfg.logger, "generate: invalid -redirectsFormat %q: want %s or empty", cfg.redirectsFormat, hostNetlify)
+	}
+
+	switch cfg.feedDescriptionMode {
+	case feedDescriptionPlain, feedDescriptionHTML:
+	default:
+		fatal(cfg.logger, "generate: invalid -feedDescriptionMode %q: want %s or %s", cfg.feedDescriptionMode, feedDescriptionPlain, feedDescriptionHTML)
+	}
+
+	switch cfg.comments.Provider {
+	case "", commentsUtterances, commentsGiscus:
+	default:
+		fatal(cfg.logger, "generate: invalid -comments %q: want %s, %s or empty", cfg.comments.Provider, commentsUtterances, commentsGiscus)
+	}
+	if cfg.comments.Repo == "" {
+		cfg.comments.Repo = cfg.githubRepo
+	}
+
+	if cfg.jsonPages && cfg.exportJSON {
+		fatal(cfg.logger, "generate: -jsonPages and -exportJSON both write <slug>/index.json; pick one")
+	}
+
+	switch cfg.analytics.Provider {
+	case "":
+	case analyticsPlausible, analyticsGoatCounter:
+		if cfg.analytics.Domain == "" {
+			fatal(cfg.logger, "generate: -analytics %s requires -analyticsDomain", cfg.analytics.Provider)
+		}
+		snippet, err := analyticsSnippet(cfg.analytics)
+		if err != nil {
+			fatal(cfg.logger, "generate: %v", err)
+		}
+		cfg.analyticsSnippet = snippet
+	default:
+		fatal(cfg.logger, "generate: invalid -analytics %q: want %s, %s or empty", cfg.analytics.Provider, analyticsPlausible, analyticsGoatCounter)
+	}
+
+	switch cfg.dateInPath {
+	case "", dateInPathYear, dateInPathMonth:
+	default:
+		fatal(cfg.logger, "generate: invalid -dateInPath %q: want %s, %s or empty", cfg.dateInPath, dateInPathYear, dateInPathMonth)
+	}
+
+	switch cfg.headersFormat {
+	case "", hostNetlify:
+	default:
+		fatal(cfg.logger, "generate: invalid -headersFormat %q: want %s or empty", cfg.headersFormat, hostNetlify)
+	}
+
+	switch cfg.env {
+	case envProduction:
+	case envPreview:
+		cfg.drafts = true
+	default:
+		fatal(cfg.logger, "generate: invalid -env %q: want %s or %s", cfg.env, envProduction, envPreview)
+	}
+
+	contentDirList, mounts, err := parseContentMounts(splitList(*contentDirs))
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.contentDirs, cfg.mounts = contentDirList, mounts
+	cfg.extensions = splitList(*extensions)
+	cfg.alwaysAssets = splitList(*alwaysAssets)
+	cfg.preload = splitList(*preload)
+	mode, err := strconv.ParseUint(*fileMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		fatal(cfg.logger, "generate: invalid -fileMode %q: want an octal permission such as 0644", *fileMode)
+	}
+	cfg.fileMode = fs.FileMode(mode)
+	cfg.lintDisable = splitList(*lintDisable)
+	cfg.a11yAllow = splitList(*a11yAllow)
+
+	loc, err := loadTimezone(*timezone)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.location = loc
+
+	now, err := buildTime()
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.now = now
+
+	if strings.TrimSpace(cfg.baseURL) == "" {
+		if cfg.strict {
+			fatal(cfg.logger, "generate: -baseURL is required with -strict")
+		}
+		cfg.logger.Warn(fmt.Sprintf("-baseURL이 지정되지 않아 %s 기준으로 링크를 만듭니다. 배포용 빌드라면 -baseURL을 지정하세요.", defaultBaseURL))
+		cfg.baseURL = defaultBaseURL
+	}
+	baseURL, err := normalizeBaseURL("baseURL", cfg.baseURL)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.baseURL = baseURL
+	if cfg.imageCDN != "" {
+		if cfg.imageCDN, err = normalizeBaseURL("imageCDN", cfg.imageCDN); err != nil {
+			fatal(cfg.logger, "generate: %v", err)
+		}
+	}
+
+	for _, snippet := range []struct {
+		dst  *template.HTML
+		path string
+		name string
+	}{
+		{&cfg.headerExtra, *headerExtra, "headerExtra.html"},
+		{&cfg.footerExtra, *footerExtra, "footerExtra.html"},
+	} {
+		path := snippet.path
+		if path == "" {
+			path = filepath.Join(cfg.templateDir, snippet.name)
+		}
+		html, err := loadSnippet(path)
+		if err != nil {
+			fatal(cfg.logger, "generate: %v", err)
+		}
+		*snippet.dst = html
+	}
+
+	data, err := loadDataDir(*dataDir)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.data = data
+
+	snippets, err := loadSnippets(cfg.snippetDir)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.snippets = snippets
+
+	authors, err := loadAuthors(*authorsFile)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.authors = authors
+
+	manifest, err := loadManifest(*manifestFile)
+	if err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+	cfg.manifest = manifest
+
+	if cfg.indexNow {
+		if !indexNowKeyPattern.MatchString(cfg.indexNowKey) {
+			fatal(cfg.logger, "generate: -indexnow needs an -indexnowKey of 8-128 letters, digits or dashes")
+		}
+		if cfg.baseURL == defaultBaseURL {
+			fatal(cfg.logger, "generate: -indexnow needs -baseURL")
+		}
+	}
+
+	if cfg.check {
+		problems := check(context.Background(), cfg)
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		if len(problems) > 0 {
+			fatal(cfg.logger, "check: %d problem(s) found", len(problems))
+		}
+		return
+	}
+
+	if err := run(context.Background(), cfg); err != nil {
+		fatal(cfg.logger, "generate: %v", err)
+	}
+}
+
+// normalizeBaseURL validates raw as an absolute http(s) URL and returns it
+// without query, fragment or trailing slash, ready to have paths appended.
+// name is the flag the value came from and is only used in errors.
+func normalizeBaseURL(name, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid -%s %q: %w", name, raw, err)
+	}
+	if u.Scheme == "" {
+		return "", fmt.Errorf("invalid -%s %q: missing scheme (did you mean https://%s?)", name, raw, strings.TrimPrefix(raw, "//"))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid -%s %q: scheme must be http or https", name, raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid -%s %q: missing host", name, raw)
+	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// fatal logs the formatted message as an error and exits with status 1.
+func fatal(logger *slog.Logger, format string, args ...any) {
+	logger.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// buildTime returns the timestamp used wherever the build needs "now". It
+// honors SOURCE_DATE_EPOCH so repeated builds of the same content are
+// byte-identical.
+func buildTime() (time.Time, error) {
+	epoch := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if epoch == "" {
+		return time.Now(), nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+func run(ctx context.Context, cfg config) error {
+	cfg.out = newBuildOutput(cfg.fileMode)
+	cfg.tagSlugs = tagSlugs{}
+	if err := ensureDir(cfg.outputDir); err != nil {
+		return err
+	}
+	unlock, err := acquireBuildLock(cfg, cfg.lockWait)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var pagesBefore map[string][sha256.Size]byte
+	if cfg.indexNow {
+		var err error
+		if pagesBefore, err = pageDigests(cfg.outputDir); err != nil {
+			return fmt.Errorf("scan output: %w", err)
+		}
+	}
+
+	if cfg.sri {
+		integrity, err := buildAssetIntegrity(cfg.assetDir)
+		if err != nil {
+			return err
+		}
+		cfg.assetIntegrity = integrity
+	}
+
+	tpls, err := loadTemplates(cfg)
+	if err != nil {
+		return err
+	}
+
+	posts, err := loadPosts(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.expired {
+		var dropped int
+		posts, dropped = dropExpired(cfg, posts)
+		if dropped > 0 {
+			cfg.logger.Info(fmt.Sprintf("만료된 게시물 %d개를 제외했습니다. 포함하려면 -expired를 사용하세요.", dropped))
+		}
+	}
+	if len(posts) == 0 {
+		cfg.logger.Info("게시물을 찾지 못했습니다. 새로운 글을 추가해 보세요.")
+		return nil
+	}
+	if cfg.gitInfo {
+		attachGitInfo(ctx, cfg, posts)
+	}
+	if cfg.csp {
+		cfg.cspPolicy = buildCSP(cfg, posts)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].Date.After(posts[j].Date)
+	})
+	listed := listedPosts(posts)
+	years := buildYearGroups(listed, cfg.language)
+	if errs := checkSlugs(posts, reservedSlugs(years)); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if errs := reportTitles(cfg, posts); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	findings := lintPosts(cfg, posts)
+	if failing := lintFailures(cfg, findings); len(failing) > 0 {
+		reportLint(cfg.logger, findings)
+		return fmt.Errorf("lint: %d finding(s)", len(failing))
+	}
+
+	tagGroups := buildTagGroups(cfg.logger, listed, cfg.tagAliases)
+	cfg.tagSlugs.add(tagGroups)
+	series := buildSeries(listed)
+	if cfg.topicNav {
+		navs := buildTopicNav(tagGroups, series)
+		for i := range posts {
+			posts[i].Nav = navs[posts[i].Slug]
+		}
+	}
+
+	fresh := freshPosts(cfg, listed)
+	for _, p := range posts {
+		if p.RedirectTo != "" {
+			if err := writeRedirectPage(cfg, p); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writePost(cfg, tpls.post, p); err != nil {
+			return err
+		}
+		if cfg.plainText {
+			if err := writePlainText(cfg, p); err != nil {
+				return err
+			}
+		}
+		if cfg.exportJSON {
+			if err := writePostExport(cfg, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := renderIndex(cfg, tpls.index, listed); err != nil {
+		return err
+	}
+	if tpls.tags == nil || tpls.tag == nil {
+		if len(tagGroups) > 0 {
+			cfg.logger.Warn(fmt.Sprintf("태그가 %d개 있지만 tags.html 또는 tag.html 템플릿이 없어 일부 태그 페이지를 만들지 않습니다.", len(tagGroups)))
+		} else {
+			cfg.logger.Info("tags.html 또는 tag.html 템플릿이 없어 태그 페이지를 건너뜁니다.")
+		}
+	}
+	if tpls.tags != nil {
+		opmlURL := ""
+		if len(feedPosts(listed)) > 0 {
+			opmlURL = opmlPath
+		}
+		if err := renderTagIndex(cfg, tpls.tags, tagGroups, opmlURL); err != nil {
+			return err
+		}
+	}
+	if tpls.tag != nil {
+		if err := renderTagPages(cfg, tpls.tag, tagGroups); err != nil {
+			return err
+		}
+	}
+	if err := renderYearPages(cfg, tpls.year, years); err != nil {
+		return err
+	}
+	if err := renderMonthPages(cfg, tpls.month, years); err != nil {
+		return err
+	}
+	if err := renderArchive(cfg, tpls.archive, years); err != nil {
+		return err
+	}
+	if tpls.series != nil {
+		if err := renderSeriesIndex(cfg, tpls.series, series); err != nil {
+			return err
+		}
+	}
+	if err := renderNotFoundPages(cfg, tpls); err != nil {
+		return err
+	}
+	if cfg.tagGraph {
+		if err := renderTagGraph(cfg, listed, tagGroups); err != nil {
+			return err
+		}
+	}
+	if err := renderRSS(cfg, listed); err != nil {
+		return err
+	}
+	if cfg.tagFeeds {
+		if err := renderTagFeeds(cfg, tagGroups); err != nil {
+			return err
+		}
+	}
+	authorGroups := buildAuthorGroups(listed)
+	if cfg.authors != nil {
+		if err := renderAuthorPages(cfg, tpls.author, authorGroups); err != nil {
+			return err
+		}
+	}
+	if cfg.authorFeeds {
+		if err := renderAuthorFeeds(cfg, authorGroups); err != nil {
+			return err
+		}
+	}
+	if len(feedPosts(listed)) > 0 {
+		if err := renderOPML(cfg, tagGroups, authorGroups); err != nil {
+			return err
+		}
+	}
+	if cfg.api {
+		if err := renderAPI(cfg, listed, tagGroups); err != nil {
+			return err
+		}
+	}
+	if cfg.searchIndex {
+		if err := renderSearchIndex(cfg, listed); err != nil {
+			return err
+		}
+		if err := renderSearch(cfg, tpls.search); err != nil {
+			return err
+		}
+	}
+	// Old tag URLs only redirect when there are tag pages to go to.
+	var movedTags []tagGroup
+	if tpls.tag != nil {
+		movedTags = tagGroups
+	}
+	if cfg.redirectsFormat != hostNetlify {
+		if err := renderAliasPages(cfg, posts, movedTags); err != nil {
+			return err
+		}
+	}
+
+	// Every page is written now. One pass over them finds the assets they
+	// reference and the -a11yCheck findings.
+	var scan pageScan
+	if cfg.referencedAssetsOnly || cfg.a11yCheck {
+		var checks []pageCheck
+		if cfg.a11yCheck {
+			checks = a11yPageChecks
+		}
+		if scan, err = scanPages(cfg, checks); err != nil {
+			return err
+		}
+	}
+	var assetStats copyStats
+	if cfg.referencedAssetsOnly {
+		assetStats, err = copyReferencedAssets(cfg, scan.assetRefs)
+	} else {
+		assetStats, err = copyAssets(cfg.out, cfg.assetDir, filepath.Join(cfg.outputDir, "assets"))
+	}
+	if err != nil {
+		return err
+	}
+	if assetStats.Files > 0 {
+		cfg.logger.Info(fmt.Sprintf("에셋 %d개(%d바이트)를 복사했습니다.", assetStats.Files, assetStats.Bytes))
+	}
+
+	if cfg.redirectsFormat == hostNetlify {
+		if err := renderNetlifyRedirects(cfg, posts, movedTags); err != nil {
+			return err
+		}
+	}
+	if cfg.headersFormat == hostNetlify {
+		if err := renderNetlifyHeaders(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.serviceWorker {
+		if err := renderServiceWorker(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.manifest != nil {
+		if err := renderManifest(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.imageReport {
+		if err := renderImageReport(cfg, posts); err != nil {
+			return err
+		}
+	}
+	if err := renderCNAME(cfg); err != nil {
+		return err
+	}
+	if githubPagesBuild(cfg) {
+		if err := renderNoJekyll(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.indexNow {
+		if err := renderIndexNowKey(cfg); err != nil {
+			return err
+		}
+	}
+	if err := writeBuildManifest(cfg); err != nil {
+		return fmt.Errorf("write build manifest: %w", err)
+	}
+
+	findings = append(findings, scan.findings...)
+	reportLint(cfg.logger, findings)
+	if failing := lintFailures(cfg, findings); len(failing) > 0 {
+		return fmt.Errorf("lint: %d finding(s)", len(failing))
+	}
+
+	if cfg.notifyURL != "" {
+		if err := notify(ctx, cfg.logger, cfg.notifyURL, buildNotifyPayload(cfg, listed, fresh)); err != nil {
+			if cfg.notifyRequired {
+				return err
+			}
+			cfg.logger.Warn(fmt.Sprintf("빌드 알림을 보내지 못했습니다: %v", err))
+		}
+	}
+
+	if cfg.indexNow {
+		pagesAfter, err := pageDigests(cfg.outputDir)
+		if err != nil {
+			return fmt.Errorf("scan output: %w", err)
+		}
+		if err := pingIndexNow(ctx, cfg, changedPageURLs(cfg.baseURL, pagesBefore, pagesAfter)); err != nil {
+			cfg.logger.Warn(fmt.Sprintf("IndexNow 알림을 보내지 못했습니다: %v", err))
+		}
+	}
+	return nil
+}
+
+func ensureDir(dir string) error {
+	return os.MkdirAll(dir, 0o755)
+}
+
+// buildOutput is the state of one run's writes to the output directory. It
+// records every path written (or found unchanged) so steps that run after
+// rendering can consult it instead of walking the output directory, which
+// may still hold pages from earlier builds.
+type buildOutput struct {
+	// fileMode is the permission generated files are written with.
+	fileMode fs.FileMode
+
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+func newBuildOutput(fileMode fs.FileMode) *buildOutput {
+	return &buildOutput{fileMode: fileMode, paths: make(map[string]bool)}
+}
+
+// writeIfChanged writes data to target unless the file already holds exactly
+// those bytes, so unchanged outputs keep their modification time and don't
+// show up in content-diffing deploys.
+func (o *buildOutput) writeIfChanged(target string, data []byte) error {
+	if old, err := os.ReadFile(target); err == nil && bytes.Equal(old, data) {
+		o.record(target)
+		return nil
+	}
+	if err := os.WriteFile(target, data, o.fileMode); err != nil {
+		return err
+	}
+	o.record(target)
+	// WriteFile only applies the mode to new files.
+	return os.Chmod(target, o.fileMode)
+}
+
+func (o *buildOutput) record(target string) {
+	o.mu.Lock()
+	o.paths[filepath.Clean(target)] = true
+	o.mu.Unlock()
+}
+
+// wasBuilt reports whether target was written by this build.
+func (o *buildOutput) wasBuilt(target string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.paths[filepath.Clean(target)]
+}
+
+// filesIn returns the sorted paths under dir written by this build.
+func (o *buildOutput) filesIn(dir string) []string {
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var paths []string
+	for p := range o.paths {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// writeText is writeIfChanged for text files: data is written ending in
+// exactly one newline.
+func (o *buildOutput) writeText(target string, data []byte) error {
+	return o.writeIfChanged(target, append(bytes.TrimRight(data, "\n"), '\n'))
+}
+
+// writeHTML executes the named template and writes the result to target
+// with writeText. Template errors are returned as they are so callers can
+// wrap them in a RenderError.
+func (o *buildOutput) writeHTML(target string, tpl *template.Template, name string, data any) error {
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	if err := o.writeText(target, buf.Bytes()); err != nil {
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+	return nil
+}
+
+// loadSnippet reads a trusted HTML fragment to inject into the layout. A
+// missing file yields an empty snippet.
+func loadSnippet(path string) (template.HTML, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read snippet %s: %w", path, err)
+	}
+	return template.HTML(data), nil
+}
+
+func loadTemplates(cfg config) (*templateBundle, error) {
+	dir := cfg.templateDir
+	layoutPath := filepath.Join(dir, "base.html")
+	indexPath := filepath.Join(dir, "index.html")
+	postPath := filepath.Join(dir, "post.html")
+	tagsIndexPath := filepath.Join(dir, "tags.html")
+	tagPath := filepath.Join(dir, "tag.html")
+	searchPath := filepath.Join(dir, "search.html")
+	yearPath := filepath.Join(dir, "year.html")
+	monthPath := filepath.Join(dir, "month.html")
+	archivePath := filepath.Join(dir, "archive.html")
+	seriesPath := filepath.Join(dir, "series.html")
+	authorPath := filepath.Join(dir, "author.html")
+	notFoundPath := filepath.Join(dir, "404.html")
+
+	layout, err := template.New("base").
+		Funcs(template.FuncMap{
+			"formatDate": func(t time.Time) string { return formatDate(t, cfg.location) },
+			// localizedDate takes an optional language, such as .Post.Lang;
+			// empty falls back to -language.
+			"localizedDate": func(t time.Time, lang ...string) string {
+				return localizedDate(firstNonEmpty(append(lang, cfg.language)...), inZone(t, cfg.location))
+			},
+			// label looks up interface text for -language in uiLabels.
+			"label": func(key string, args ...any) string {
+				return uiLabel(cfg.language, key, args...)
+			},
+			"timeNow":   func() time.Time { return cfg.now },
+			"relURL":    func(u string) string { return relURL(cfg, u) },
+			"postURL":   func(slug string) string { return relURL(cfg, "/"+slug+"/") },
+			"tagURL":    func(name string) string { return relURL(cfg, cfg.tagSlugs.url(cfg.tagAliases.resolve(name))) },
+			"tagName":   cfg.tagAliases.resolve,
+			"integrity": integrityFunc(cfg),
+		}).
+		ParseFiles(layoutPath)
+	if err != nil {
+		return nil, &TemplateError{Name: "base", Path: layoutPath, Err: err}
+	}
+
+	index, err := parsePage(layout, "index", indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := parsePage(layout, "post", postPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tagsIndex, err := parseOptionalPage(layout, "tags", tagsIndexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := parseOptionalPage(layout, "tag", tagPath)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := parseOptionalPage(layout, "search", searchPath)
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		if search, err = parseEmbeddedPage(layout, "search", defaultSearchTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	year, err := parseOptionalPage(layout, "year", yearPath)
+	if err != nil {
+		return nil, err
+	}
+	if year == nil {
+		if year, err = parseEmbeddedPage(layout, "year", defaultYearTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	month, err := parseOptionalPage(layout, "month", monthPath)
+	if err != nil {
+		return nil, err
+	}
+	if month == nil {
+		if month, err = parseEmbeddedPage(layout, "month", defaultMonthTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := parseOptionalPage(layout, "archive", archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if archive == nil {
+		if archive, err = parseEmbeddedPage(layout, "archive", defaultArchiveTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	series, err := parseOptionalPage(layout, "series", seriesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := parseOptionalPage(layout, "author", authorPath)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil {
+		if author, err = parseEmbeddedPage(layout, "author", defaultAuthorTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	notFound, err := parseOptionalPage(layout, "404", notFoundPath)
+	if err != nil {
+		return nil, err
+	}
+	sectionNotFound, err := parseSectionNotFound(layout, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateBundle{
+		layout:  layout,
+		index:   index,
+		post:    post,
+		tags:    tagsIndex,
+		tag:     tag,
+		search:  search,
+		year:    year,
+		month:   month,
+		archive: archive,
+		series:  series,
+		author:  author,
+
+		notFound:        notFound,
+		sectionNotFound: sectionNotFound,
+	}, nil
+}
+
+// parsePage clones the base layout and adds the page template at path to it.
+// Clone errors are returned rather than panicking so a broken layout is
+// reported like any other template problem.
+func parsePage(layout *template.Template, name, path string) (*template.Template, error) {
+	tpl, err := layout.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("clone base template for %s: %w", name, err)
+	}
+	tpl, err = tpl.ParseFiles(path)
+	if err != nil {
+		return nil, &TemplateError{Name: name, Path: path, Err: err}
+	}
+	return tpl, nil
 }
 
-type rssFeed struct {
-	XMLName   xml.Name   `xml:"rss"`
-	Version   string     `xml:"version,attr"`
-	XMLNSAtom string     `xml:"xmlns:atom,attr"`
-	Channel   rssChannel `xml:"channel"`
+// parseOptionalPage is parsePage for templates a site may leave out. A
+// missing file yields a nil template and no error.
+func parseOptionalPage(layout *template.Template, name, path string) (*template.Template, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return parsePage(layout, name, path)
 }
 
-type rssChannel struct {
-	Title         string    `xml:"title"`
-	Link          string    `xml:"link"`
-	Description   string    `xml:"description"`
-	Language      string    `xml:"language,omitempty"`
-	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
-	AtomLink      atomLink  `xml:"atom:link"`
-	Items         []rssItem `xml:"item"`
+// parseEmbeddedPage is parsePage for the built-in fallback templates used
+// when a site does not provide its own file.
+func parseEmbeddedPage(layout *template.Template, name, src string) (*template.Template, error) {
+	tpl, err := layout.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("clone base template for %s: %w", name, err)
+	}
+	tpl, err = tpl.Parse(src)
+	if err != nil {
+		return nil, &TemplateError{Name: name, Err: err}
+	}
+	return tpl, nil
 }
 
-type atomLink struct {
-	Href string `xml:"href,attr"`
-	Rel  string `xml:"rel,attr"`
-	Type string `xml:"type,attr"`
+func newMarkdown(cfg config) goldmark.Markdown {
+	extensions := []goldmark.Extender{extension.GFM, taskListExtension{}}
+	if cfg.numberHeadings {
+		extensions = append(extensions, headingNumberExtension{})
+	}
+	if len(cfg.fenceRenderers) > 0 {
+		extensions = append(extensions, &fenceRendererExtension{
+			logger:   cfg.logger,
+			commands: cfg.fenceRenderers,
+			timeout:  cfg.fenceTimeout,
+		})
+	}
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
 }
 
-type rssItem struct {
-	Title       string  `xml:"title"`
-	Link        string  `xml:"link"`
-	GUID        rssGUID `xml:"guid"`
-	PubDate     string  `xml:"pubDate"`
-	Description string  `xml:"description"`
-}
+func loadPosts(ctx context.Context, cfg config) ([]post, error) {
+	var posts []post
+	md := newMarkdown(cfg)
+
+	err := walkContent(ctx, cfg, func(root, path string) error {
+		p, ok, err := loadPost(ctx, md, cfg, root, path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			posts = append(posts, p)
+		}
+		return nil
+	})
 
-type rssGUID struct {
-	IsPermaLink string `xml:"isPermaLink,attr,omitempty"`
-	Value       string `xml:",chardata"`
+	return posts, err
 }
 
-const githubRepo = "yoonhyunwoo/blog"
+// walkContent calls fn for every content file under each content directory,
+// passing the root the file was found in. It stops early when ctx is
+// cancelled.
+func walkContent(ctx context.Context, cfg config, fn func(root, path string) error) error {
+	for _, root := range cfg.contentDirs {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if d.IsDir() || !hasContentExt(d.Name(), cfg.extensions) {
+				return nil
+			}
 
-func main() {
-	cfg := config{}
-	flag.StringVar(&cfg.contentDir, "content", "content", "Markdown content directory")
-	flag.StringVar(&cfg.templateDir, "templates", "templates", "HTML template directory")
-	flag.StringVar(&cfg.assetDir, "assets", "assets", "Static asset directory")
-	flag.StringVar(&cfg.outputDir, "out", "public", "Build output directory")
-	flag.StringVar(&cfg.baseURL, "baseURL", "https://example.com", "Base URL used for absolute links in RSS (e.g. https://thumbgo.dev)")
-	flag.Parse()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
 
-	cfg.baseURL = strings.TrimRight(cfg.baseURL, "/")
-	if cfg.baseURL == "" {
-		cfg.baseURL = "https://example.com"
+			return fn(root, path)
+		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if err := run(context.Background(), cfg); err != nil {
-		log.Fatalf("generate: %v", err)
+func hasContentExt(name string, exts []string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range exts {
+		if strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+			return true
+		}
 	}
+	return false
 }
 
-func run(ctx context.Context, cfg config) error {
-	if err := ensureDir(cfg.outputDir); err != nil {
-		return err
+// loadPost reads and renders a single markdown file found under root. The
+// boolean result is false when the file is a draft and should be left out
+// of the build.
+func loadPost(ctx context.Context, md goldmark.Markdown, cfg config, root, path string) (post, bool, error) {
+	draftFile := cfg.draftPrefix != "" && strings.HasPrefix(filepath.Base(path), cfg.draftPrefix)
+	if draftFile && !cfg.drafts {
+		return post{}, false, nil
 	}
 
-	tpls, err := loadTemplates(cfg.templateDir)
+	src, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return post{}, false, fmt.Errorf("read %s: %w", path, err)
 	}
 
-	posts, err := loadPosts(ctx, cfg, tpls.post)
+	fm, body, err := splitFrontMatter(src)
 	if err != nil {
-		return err
+		return post{}, false, &FrontMatterError{Path: path, Err: err}
 	}
-	if len(posts) == 0 {
-		log.Println("게시물을 찾지 못했습니다. 새로운 글을 추가해 보세요.")
-		return nil
+	bodyLine := bytes.Count(src[:len(src)-len(body)], []byte("\n"))
+	applyTimezone(&fm, cfg.location)
+	fm.Draft = fm.Draft || draftFile
+	if fm.Draft && !cfg.drafts {
+		return post{}, false, nil
 	}
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].Date.After(posts[j].Date)
-	})
 
-	if err := renderIndex(cfg.outputDir, tpls.index, posts); err != nil {
-		return err
-	}
-	tagGroups := buildTagGroups(posts)
-	if err := renderTagIndex(cfg.outputDir, tpls.tags, tagGroups); err != nil {
-		return err
-	}
-	if err := renderTagPages(cfg.outputDir, tpls.tag, tagGroups); err != nil {
-		return err
-	}
-	if err := renderRSS(cfg, posts); err != nil {
-		return err
-	}
-	if err := copyAssets(cfg.assetDir, filepath.Join(cfg.outputDir, "assets")); err != nil {
-		return err
+	if !fm.Draft && !fm.AllowEmpty && len(bytes.TrimSpace(body)) == 0 {
+		if cfg.strict {
+			return post{}, false, fmt.Errorf("%s has no body (set allowEmpty: true for an intentionally empty page)", path)
+		}
+		cfg.logger.Warn(fmt.Sprintf("%s 본문이 비어 있습니다. 의도한 빈 페이지라면 allowEmpty: true를 지정하세요.", path))
 	}
-	return nil
-}
-
-func ensureDir(dir string) error {
-	return os.MkdirAll(dir, 0o755)
-}
-
-func loadTemplates(dir string) (*templateBundle, error) {
-	layoutPath := filepath.Join(dir, "base.html")
-	indexPath := filepath.Join(dir, "index.html")
-	postPath := filepath.Join(dir, "post.html")
-	tagsIndexPath := filepath.Join(dir, "tags.html")
-	tagPath := filepath.Join(dir, "tag.html")
 
-	layout, err := template.New("base").
-		Funcs(template.FuncMap{
-			"formatDate": formatDate,
-			"timeNow":    time.Now,
-			"tagURL":     tagURL,
-		}).
-		ParseFiles(layoutPath)
+	// body stays as written for ContentRaw and the markdown exports; source
+	// is what gets rendered.
+	source, err := expandShortcodes(cfg, root, body)
 	if err != nil {
-		return nil, fmt.Errorf("parse base template: %w", err)
+		return post{}, false, fmt.Errorf("shortcodes %s: %w", path, err)
 	}
 
-	index, err := template.Must(layout.Clone()).ParseFiles(indexPath)
-	if err != nil {
-		return nil, fmt.Errorf("parse index template: %w", err)
+	slug := buildSlug(root, path)
+	if draftFile {
+		i := strings.LastIndex(slug, "/") + 1
+		slug = slug[:i] + strings.TrimPrefix(slug[i:], strings.ToLower(cfg.draftPrefix))
+	}
+	slug = datePrefix(cfg.dateInPath, fm.Date) + slug
+	if prefix := cfg.mounts[root].Prefix; prefix != "" {
+		slug = prefix + "/" + slug
 	}
 
-	post, err := template.Must(layout.Clone()).ParseFiles(postPath)
+	pc := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	if fm.NumberHeadings != nil {
+		pc.Set(numberHeadingsKey, *fm.NumberHeadings)
+	}
+	htmlContent, doc, err := renderMarkdownTimeout(ctx, cfg.renderTimeout, md, source, parser.WithContext(pc))
 	if err != nil {
-		return nil, fmt.Errorf("parse post template: %w", err)
+		return post{}, false, fmt.Errorf("markdown %s: %w", path, err)
 	}
 
-	tagsIndex, err := template.Must(layout.Clone()).ParseFiles(tagsIndexPath)
+	redirectTo, err := redirectTarget(fm.RedirectTo)
 	if err != nil {
-		return nil, fmt.Errorf("parse tags template: %w", err)
+		return post{}, false, &FrontMatterError{Path: path, Err: err}
+	}
+	if redirectTo != "" && fm.Draft {
+		cfg.logger.Info(fmt.Sprintf("%s: 초안이라 redirect_to를 적용하지 않고 일반 페이지로 만듭니다.", path))
+		redirectTo = ""
 	}
 
-	tag, err := template.Must(layout.Clone()).ParseFiles(tagPath)
+	outputs, err := resolveOutputs(cfg, fm.Outputs)
 	if err != nil {
-		return nil, fmt.Errorf("parse tag template: %w", err)
+		return post{}, false, &FrontMatterError{Path: path, Err: err}
 	}
 
-	return &templateBundle{
-		layout: layout,
-		index:  index,
-		post:   post,
-		tags:   tagsIndex,
-		tag:    tag,
-	}, nil
-}
-
-func loadPosts(ctx context.Context, cfg config, postTpl *template.Template) ([]post, error) {
-	var posts []post
-	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
-		goldmark.WithRendererOptions(html.WithUnsafe()),
-	)
+	author := strings.TrimSpace(fm.Author)
 
-	err := filepath.WalkDir(cfg.contentDir, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
-			return nil
+	var profile *authorProfile
+	if cfg.authors != nil && author != "" {
+		if profile = cfg.authors[author]; profile == nil {
+			return post{}, false, &FrontMatterError{Path: path, Err: fmt.Errorf("unknown author %q: not a key of the authors file", author)}
 		}
+		author = profile.Name
+	}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+	p := post{
+		Slug:        slug,
+		Title:       pickTitle(fm, slug),
+		Date:        fm.Date,
+		Tags:        fm.Tags,
+		Summary:     fm.Summary,
+		Description: fm.Description,
+		Draft:       fm.Draft,
+		Unlisted:    fm.Unlisted,
+		Aliases:     fm.Aliases,
+		RedirectTo:  redirectTo,
+		Thumbnail:   thumbnailURL(cfg, slug, firstNonEmpty(fm.Thumbnail, firstImage(doc))),
+		ContentHTML: template.HTML(rewriteImageSources(htmlContent.String(), cfg.imageCDN)),
+		ContentRaw:  body,
+		PlainText: plainText(doc, source, plainTextOptions{
+			includeCode:   cfg.plainTextCode,
+			includeImages: cfg.plainTextImages,
+		}),
+		SourcePath: path,
+		EditURL:    editURL(cfg, mountRepo(cfg, root), path),
+		repo:       mountRepo(cfg, root),
+		ExpiryDate: fm.ExpiryDate,
+		LastMod:    fm.LastMod,
+		Comments:   postComments{Enabled: commentsEnabled(cfg, fm)},
+		Lang:       fm.Lang,
+		Author:     author,
+
+		AuthorProfile: profile,
+		Analytics:     fm.Analytics == nil || *fm.Analytics,
+		Series:        strings.TrimSpace(fm.Series),
+		SeriesPart:    fm.SeriesPart,
+		Feed:          fm.Feed == nil || *fm.Feed,
+		Headings:      extractHeadings(doc, source),
+		doc:           doc,
+		source:        source,
+		src:           src,
+		outputs:       outputs,
+		bodyLine:      bodyLine,
+	}
+	p.ReadingTime = readingTime(plainText(doc, source, plainTextOptions{}))
+	excerpt, err := postExcerpt(summaryMarkdown(), p, cfg.siteExcerptLength, cfg.siteExcerptHTML)
+	if err != nil {
+		return post{}, false, fmt.Errorf("excerpt %s: %w", path, err)
+	}
+	if cfg.siteExcerptHTML {
+		// Listings show the excerpt inline, so a lone paragraph is unwrapped.
+		if strings.HasPrefix(excerpt, "<p>") && strings.Count(excerpt, "<p>") == 1 {
+			excerpt = strings.TrimSuffix(strings.TrimPrefix(excerpt, "<p>"), "</p>")
 		}
+	} else {
+		excerpt = stdhtml.EscapeString(excerpt)
+	}
+	p.SiteExcerpt = template.HTML(excerpt)
+	p.MarkdownURL = outputURL(p, "markdown")
+	p.TextURL = outputURL(p, "text")
+	if cfg.collectLinks {
+		p.Links = collectLinks(cfg.baseURL, p.ContentHTML)
+	}
+	if !outputs["html"] && !p.Unlisted && !p.Draft {
+		cfg.logger.Warn(fmt.Sprintf("%s: html 출력을 끈 글이 목록에 남아 있어 링크가 깨질 수 있습니다. unlisted: true를 함께 쓰세요.", path))
+	}
+	return p, true, nil
+}
 
-		src, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("read %s: %w", path, err)
+// dropExpired removes posts whose expiryDate is at or before the build time
+// and reports how many were removed.
+func dropExpired(cfg config, posts []post) ([]post, int) {
+	kept := posts[:0]
+	for _, p := range posts {
+		if !p.ExpiryDate.IsZero() && !p.ExpiryDate.After(cfg.now) {
+			continue
 		}
+		kept = append(kept, p)
+	}
+	return kept, len(posts) - len(kept)
+}
 
-		fm, body, err := splitFrontMatter(src)
-		if err != nil {
-			return fmt.Errorf("front matter %s: %w", path, err)
-		}
-		if fm.Draft {
-			return nil
+// listedPosts drops unlisted and redirecting posts. Unlisted posts still get
+// their own page but never appear in the index, archives, tag pages, feeds
+// or search.
+func listedPosts(posts []post) []post {
+	out := make([]post, 0, len(posts))
+	for _, p := range posts {
+		if !p.Unlisted && p.RedirectTo == "" {
+			out = append(out, p)
 		}
+	}
+	return out
+}
 
-		slug := buildSlug(cfg.contentDir, path)
-
-		htmlContent, err := renderMarkdown(md, body)
-		if err != nil {
-			return fmt.Errorf("markdown %s: %w", path, err)
+// checkSlugs reports every slug produced by more than one source file, or
+// claimed by a page the generator writes itself. reserved maps such slugs to
+// a description of the generated page.
+func checkSlugs(posts []post, reserved map[string]string) []error {
+	seen := make(map[string]string, len(posts)+len(reserved))
+	for slug, owner := range reserved {
+		seen[slug] = owner
+	}
+	var errs []error
+	for _, p := range posts {
+		if prev, ok := seen[p.Slug]; ok {
+			errs = append(errs, fmt.Errorf("slug %q is used by both %s and %s", p.Slug, prev, p.SourcePath))
+			continue
 		}
-
-		post := post{
-			Slug:        slug,
-			Title:       pickTitle(fm, slug),
-			Date:        fm.Date,
-			Tags:        fm.Tags,
-			Summary:     fm.Summary,
-			Description: fm.Description,
-			Draft:       fm.Draft,
-			ContentHTML: template.HTML(htmlContent.String()),
-			ContentRaw:  body,
-			SourcePath:  path,
+		seen[p.Slug] = p.SourcePath
+	}
+	for _, p := range posts {
+		for _, a := range p.Aliases {
+			slug := aliasSlug(a)
+			if prev, ok := seen[slug]; ok {
+				errs = append(errs, fmt.Errorf("alias %q of %s collides with %s", a, p.SourcePath, prev))
+				continue
+			}
+			seen[slug] = "an alias in " + p.SourcePath
 		}
+	}
+	return errs
+}
 
-		if err := writePost(cfg, postTpl, post); err != nil {
-			return err
+// checkTitles reports every title shared by more than one non-draft post,
+// compared after trimming, collapsing spaces and folding case.
+func checkTitles(posts []post) []error {
+	seen := make(map[string]string, len(posts))
+	var errs []error
+	for _, p := range posts {
+		if p.Draft {
+			continue
 		}
-		posts = append(posts, post)
-		return nil
-	})
-
-	return posts, err
+		key := strings.ToLower(strings.Join(strings.Fields(p.Title), " "))
+		if prev, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("title %q is used by both %s and %s", p.Title, prev, p.SourcePath))
+			continue
+		}
+		seen[key] = p.SourcePath
+	}
+	return errs
 }
 
-func renderIndex(outDir string, tpl *template.Template, posts []post) error {
-	target := filepath.Join(outDir, "index.html")
-	if err := ensureDir(filepath.Dir(target)); err != nil {
-		return err
+// reportTitles applies -uniqueTitles: duplicates are warnings, or errors
+// with -strict since titles are legitimately reused across series.
+func reportTitles(cfg config, posts []post) []error {
+	if !cfg.uniqueTitles {
+		return nil
 	}
-	fh, err := os.Create(target)
-	if err != nil {
-		return fmt.Errorf("create index: %w", err)
+	errs := checkTitles(posts)
+	if cfg.strict {
+		return errs
 	}
-	defer fh.Close()
-	data := map[string]any{
-		"Title": "썸고 블로그",
-		"Posts": posts,
+	for _, err := range errs {
+		cfg.logger.Warn(fmt.Sprintf("제목이 중복됩니다: %v", err))
+	}
+	return nil
+}
+
+func renderIndex(cfg config, tpl *template.Template, posts []post) error {
+	total := len(posts)
+	if cfg.pageSize <= 0 && cfg.indexLimit > 0 && total > cfg.indexLimit {
+		posts = posts[:cfg.indexLimit]
 	}
-	if err := tpl.ExecuteTemplate(fh, "base", data); err != nil {
-		return fmt.Errorf("render index: %w", err)
+	for _, page := range paginate("/", posts, cfg.pageSize) {
+		target := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(pageURL("/", page.CurrentPage), "/")), "index.html")
+		if err := ensureDir(filepath.Dir(target)); err != nil {
+			return err
+		}
+		title := cfg.title
+		if page.CurrentPage > 1 {
+			title = uiLabel(cfg.language, "pageTitle", title, page.CurrentPage, page.TotalPages)
+		}
+		data := map[string]any{
+			"Title":      title,
+			"Posts":      page.Posts,
+			"Paginator":  page,
+			"TotalPosts": total,
+			"HasMore":    cfg.pageSize <= 0 && len(posts) < total,
+			"ArchiveURL": archiveURL,
+			"Site":       newSite(cfg),
+		}
+		if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+			return &RenderError{Kind: "index", Target: target, Err: err}
+		}
 	}
 	return nil
 }
 
-func renderTagIndex(outDir string, tpl *template.Template, tags []tagGroup) error {
-	dir := filepath.Join(outDir, "tags")
+// nonEmptyTagGroups drops tags left without posts after filtering, so no
+// empty tag page or index entry is ever rendered.
+func nonEmptyTagGroups(tags []tagGroup) []tagGroup {
+	out := make([]tagGroup, 0, len(tags))
+	for _, tag := range tags {
+		if len(tag.Posts) > 0 {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// renderTagIndex writes /tags/. opmlURL is the site path of the OPML export,
+// or empty when the build writes none.
+func renderTagIndex(cfg config, tpl *template.Template, tags []tagGroup, opmlURL string) error {
+	tags = nonEmptyTagGroups(tags)
+	dir := filepath.Join(cfg.outputDir, "tags")
 	if err := ensureDir(dir); err != nil {
 		return err
 	}
 	target := filepath.Join(dir, "index.html")
-	fh, err := os.Create(target)
-	if err != nil {
-		return fmt.Errorf("create tag index: %w", err)
-	}
-	defer fh.Close()
 	data := map[string]any{
-		"Title": "태그 모음",
-		"Tags":  tags,
+		"Title":   uiLabel(cfg.language, "tagIndex"),
+		"Tags":    tags,
+		"OPMLURL": opmlURL,
+		"Site":    newSite(cfg),
 	}
-	if err := tpl.ExecuteTemplate(fh, "base", data); err != nil {
-		return fmt.Errorf("render tag index: %w", err)
+	if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+		return &RenderError{Kind: "tag index", Target: target, Err: err}
 	}
 	return nil
 }
 
-func renderTagPages(outDir string, tpl *template.Template, tags []tagGroup) error {
+func renderTagPages(cfg config, tpl *template.Template, tags []tagGroup) error {
+	tags = nonEmptyTagGroups(tags)
 	if len(tags) == 0 {
 		return nil
 	}
-	dir := filepath.Join(outDir, "tags")
+	size := cfg.pageSize
+	if cfg.tagPageSize >= 0 {
+		size = cfg.tagPageSize
+	}
 	for _, tag := range tags {
-		tagDir := filepath.Join(dir, tag.Slug)
-		if err := ensureDir(tagDir); err != nil {
-			return err
-		}
-		target := filepath.Join(tagDir, "index.html")
-		fh, err := os.Create(target)
-		if err != nil {
-			return fmt.Errorf("create tag page: %w", err)
-		}
-		data := map[string]any{
-			"Title": fmt.Sprintf("태그: %s", tag.Name),
-			"Tag":   tag,
-			"Posts": tag.Posts,
-		}
-		if execErr := tpl.ExecuteTemplate(fh, "base", data); execErr != nil {
-			fh.Close()
-			return fmt.Errorf("render tag %s: %w", tag.Name, execErr)
-		}
-		if err := fh.Close(); err != nil {
-			return err
+		base := "/tags/" + tag.Slug + "/"
+		for _, page := range paginate(base, tag.Posts, size) {
+			tagDir := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.Trim(pageURL(base, page.CurrentPage), "/")))
+			if err := ensureDir(tagDir); err != nil {
+				return err
+			}
+			target := filepath.Join(tagDir, "index.html")
+			title := uiLabel(cfg.language, "tagTitle", tag.Name)
+			if page.CurrentPage > 1 {
+				title = uiLabel(cfg.language, "pageTitle", title, page.CurrentPage, page.TotalPages)
+			}
+			data := map[string]any{
+				"Title":     title,
+				"Tag":       tag,
+				"Posts":     page.Posts,
+				"Paginator": page,
+				"Feeds":     tagFeedLinks(cfg, tag),
+				"Site":      newSite(cfg),
+			}
+			if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+				return &RenderError{Kind: "tag", Slug: tag.Name, Target: target, Err: err}
+			}
 		}
 	}
 	return nil
 }
 
 func writePost(cfg config, tpl *template.Template, post post) error {
-	targetDir := filepath.Join(cfg.outputDir, post.Slug)
+	targetDir := filepath.Join(cfg.outputDir, filepath.FromSlash(post.Slug))
 	if err := ensureDir(targetDir); err != nil {
 		return err
 	}
-	target := filepath.Join(targetDir, "index.html")
-	fh, err := os.Create(target)
-	if err != nil {
-		return fmt.Errorf("create %s: %w", target, err)
+	if post.outputs["html"] {
+		target := filepath.Join(targetDir, "index.html")
+		if err := cfg.out.writeHTML(target, tpl, "base", postData(cfg, post)); err != nil {
+			return &RenderError{Kind: "post", Slug: post.Slug, Target: target, Err: err}
+		}
 	}
-	defer fh.Close()
+	return writeOutputs(cfg, post)
+}
 
+func postData(cfg config, post post) map[string]any {
 	data := map[string]any{
 		"Title":       post.Title,
 		"Post":        post,
 		"Description": firstNonEmpty(post.Description, post.Summary),
 		"BaseURL":     cfg.baseURL,
-		"GithubRepo":  githubRepo,
+		"GithubRepo":  cfg.githubRepo,
+		"Site":        newSite(cfg),
+		"Lang":        firstNonEmpty(post.Lang, cfg.language),
+		"Locale":      ogLocale(firstNonEmpty(post.Lang, cfg.language)),
+		"TagNav":      post.Nav.Tags,
+		"SeriesNav":   post.Nav.Series,
 	}
-	if err := tpl.ExecuteTemplate(fh, "base", data); err != nil {
-		return fmt.Errorf("render post: %w", err)
+	if !post.Analytics {
+		s := data["Site"].(site)
+		s.Analytics = nil
+		s.AnalyticsSnippet = ""
+		data["Site"] = s
 	}
-	return nil
+	if post.Draft {
+		data["Title"] = "[DRAFT] " + post.Title
+		data["DraftBanner"] = cfg.draftBanner
+	}
+	return data
 }
 
-func buildTagGroups(posts []post) []tagGroup {
+// buildTagGroups groups posts by tag key. Tags listed in aliases fold into
+// their canonical tag, whose name and slug are used for the group. Tags
+// whose slugs collide are told apart by assignTagSlugs.
+func buildTagGroups(logger *slog.Logger, posts []post, aliases tagAliases) []tagGroup {
+	canonicals := make(map[string]string, len(aliases))
+	for _, canonical := range aliases {
+		canonicals[tagKey(canonical)] = canonical
+	}
+	warned := make(map[string]bool)
+
 	groupMap := make(map[string]*tagGroup)
 	seen := make(map[string]struct{})
 	for _, p := range posts {
@@ -389,17 +1809,26 @@ func buildTagGroups(posts []post) []tagGroup {
 			if name == "" {
 				continue
 			}
-			slug := tagSlug(name)
-			key := slug + "@" + p.Slug
-			if _, ok := seen[key]; ok {
+			name = aliases.resolve(name)
+			if canonical, ok := canonicals[tagKey(name)]; ok && name != canonical {
+				if !warned[name] {
+					warned[name] = true
+					logger.Warn(fmt.Sprintf("태그 %q가 별칭 대상 %q와 표기만 다르게 직접 쓰였습니다. %q로 합칩니다.", name, canonical, canonical))
+				}
+				name = canonical
+			}
+			key := tagKey(name)
+			if _, ok := seen[key+"@"+p.Slug]; ok {
 				continue
 			}
-			seen[key] = struct{}{}
+			seen[key+"@"+p.Slug] = struct{}{}
 
-			group, ok := groupMap[slug]
+			group, ok := groupMap[key]
 			if !ok {
-				group = &tagGroup{Name: name, Slug: slug}
-				groupMap[slug] = group
+				group = &tagGroup{Name: name, key: key}
+				groupMap[key] = group
+			} else if !strings.EqualFold(group.Name, name) {
+				logger.Warn(fmt.Sprintf("태그 %q와 %q가 같은 태그(%s)로 합쳐집니다.", group.Name, name, key))
 			}
 			group.Posts = append(group.Posts, p)
 		}
@@ -416,6 +1845,7 @@ func buildTagGroups(posts []post) []tagGroup {
 		})
 		result = append(result, *g)
 	}
+	assignTagSlugs(logger, result)
 
 	sort.Slice(result, func(i, j int) bool {
 		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
@@ -423,132 +1853,100 @@ func buildTagGroups(posts []post) []tagGroup {
 	return result
 }
 
-func plainExcerpt(src []byte, limit int) string {
-	text := strings.TrimSpace(string(src))
-	if text == "" {
-		return ""
-	}
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-	text = strings.ReplaceAll(text, "\n", " ")
-	text = strings.Join(strings.Fields(text), " ")
-	if limit <= 0 {
-		return text
+// assignTagSlugs sets the slug of every group. When several tags share a
+// slug, the one with the smallest key keeps it and the others get the first
+// free "-2", "-3", ... suffix, so the outcome depends only on the set of
+// tags, not on post order.
+func assignTagSlugs(logger *slog.Logger, groups []tagGroup) {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+	taken := make(map[string]bool, len(groups))
+	var clashes []int
+	for i := range groups {
+		slug := tagSlug(groups[i].Name)
+		if taken[slug] {
+			clashes = append(clashes, i)
+			continue
+		}
+		taken[slug] = true
+		groups[i].Slug = slug
 	}
-	runes := []rune(text)
-	if len(runes) > limit {
-		return strings.TrimSpace(string(runes[:limit])) + "…"
+	for _, i := range clashes {
+		base := tagSlug(groups[i].Name)
+		slug := base
+		for n := 2; taken[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[slug] = true
+		groups[i].Slug = slug
+		logger.Warn(fmt.Sprintf("태그 %q의 주소 %s가 다른 태그와 겹쳐 %s를 씁니다.", groups[i].Name, tagPath(base), tagPath(slug)))
 	}
-	return text
 }
 
-func renderRSS(cfg config, posts []post) error {
-	if len(posts) == 0 {
-		return nil
-	}
-
-	rssDir := filepath.Join(cfg.outputDir, "feeds")
-	if err := ensureDir(rssDir); err != nil {
-		return err
-	}
-	target := filepath.Join(rssDir, "rss.xml")
-	fh, err := os.Create(target)
-	if err != nil {
-		return fmt.Errorf("create rss feed: %w", err)
-	}
-	defer fh.Close()
-
-	base := cfg.baseURL
-	if base == "" {
-		base = "https://example.com"
-	}
-
-	channel := rssChannel{
-		Title:         "썸고 블로그",
-		Link:          base,
-		Description:   "DevOps 엔지니어 썸고(thumbgo)의 블로그",
-		Language:      "ko",
-		LastBuildDate: formatRFC1123(posts[0].Date),
-		AtomLink: atomLink{
-			Href: base + "/feeds/rss.xml",
-			Rel:  "self",
-			Type: "application/rss+xml",
-		},
-	}
-
-	const maxItems = 50
-	for i, p := range posts {
-		if i >= maxItems {
-			break
-		}
-		link := base + "/" + p.Slug + "/"
-		description := firstNonEmpty(
-			p.Summary,
-			p.Description,
-			plainExcerpt(p.ContentRaw, 200),
-		)
-		channel.Items = append(channel.Items, rssItem{
-			Title:       p.Title,
-			Link:        link,
-			GUID:        rssGUID{IsPermaLink: "true", Value: link},
-			PubDate:     formatRFC1123(p.Date),
-			Description: description,
-		})
-	}
-
-	feed := rssFeed{
-		Version:   "2.0",
-		XMLNSAtom: "http://www.w3.org/2005/Atom",
-		Channel:   channel,
+// renderMarkdown converts src to HTML and also returns the parsed document so
+// callers can walk the AST without parsing the source a second time.
+func renderMarkdown(md goldmark.Markdown, src []byte, opts ...parser.ParseOption) (*bytes.Buffer, ast.Node, error) {
+	doc := md.Parser().Parse(text.NewReader(src), opts...)
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, nil, err
 	}
+	return &buf, doc, nil
+}
 
-	if _, err := fh.WriteString(xml.Header); err != nil {
-		return fmt.Errorf("write xml header: %w", err)
+// renderMarkdownTimeout is renderMarkdown bounded by timeout and ctx.
+// goldmark cannot be interrupted, so a conversion that overruns is abandoned
+// in the background and an error returned instead.
+func renderMarkdownTimeout(ctx context.Context, timeout time.Duration, md goldmark.Markdown, src []byte, opts ...parser.ParseOption) (*bytes.Buffer, ast.Node, error) {
+	if timeout <= 0 {
+		return renderMarkdown(md, src, opts...)
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	enc := xml.NewEncoder(fh)
-	enc.Indent("", "  ")
-	if err := enc.Encode(feed); err != nil {
-		return fmt.Errorf("encode rss feed: %w", err)
-	}
-	if err := enc.Flush(); err != nil {
-		return fmt.Errorf("flush rss feed: %w", err)
+	type result struct {
+		buf *bytes.Buffer
+		doc ast.Node
+		err error
 	}
-	return nil
-}
-
-func renderMarkdown(md goldmark.Markdown, src []byte) (*bytes.Buffer, error) {
-	var buf bytes.Buffer
-	if err := md.Convert(src, &buf); err != nil {
-		return nil, err
+	done := make(chan result, 1)
+	go func() {
+		buf, doc, err := renderMarkdown(md, src, opts...)
+		done <- result{buf, doc, err}
+	}()
+	select {
+	case r := <-done:
+		return r.buf, r.doc, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, nil, fmt.Errorf("render timed out after %s", timeout)
+		}
+		return nil, nil, ctx.Err()
 	}
-	return &buf, nil
 }
 
 func splitFrontMatter(data []byte) (frontMatter, []byte, error) {
 	var fm frontMatter
-	var start int
-	switch {
-	case bytes.HasPrefix(data, []byte("---\r\n")):
-		start = len("---\r\n")
-	case bytes.HasPrefix(data, []byte("---\n")):
-		start = len("---\n")
-	default:
+	// Blank lines and indentation before the opening fence are tolerated;
+	// some editors leave them behind.
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	first := trimmed
+	start := len(trimmed)
+	if i := bytes.IndexByte(trimmed, '\n'); i >= 0 {
+		first = trimmed[:i]
+		start = i + 1
+	}
+	if string(bytes.TrimRight(first, " \t\r")) != "---" {
 		return fm, data, nil
 	}
 
-	remaining := data[start:]
-	end := bytes.Index(remaining, []byte("\n---"))
-	sepLen := len("\n---")
+	remaining := trimmed[start:]
+	end, next := findClosingDelimiter(remaining)
 	if end == -1 {
-		end = bytes.Index(remaining, []byte("\r\n---"))
-		sepLen = len("\r\n---")
-	}
-	if end == -1 {
-		return fm, nil, fmt.Errorf("unterminated front matter")
+		return fm, nil, fmt.Errorf("unterminated front matter near:\n%s", leadingLines(data, 5))
 	}
 
 	meta := remaining[:end]
-	body := remaining[end+sepLen:]
+	body := remaining[next:]
 	body = bytes.TrimLeft(body, "\r\n")
 
 	if err := yaml.Unmarshal(meta, &fm); err != nil {
@@ -557,28 +1955,99 @@ func splitFrontMatter(data []byte) (frontMatter, []byte, error) {
 	if fm.Date.IsZero() {
 		return fm, nil, fmt.Errorf("date is required in front matter")
 	}
+	fm.zoneless = zonelessDates(meta)
 
 	return fm, body, nil
 }
 
-func buildSlug(root, path string) string {
-	rel, err := filepath.Rel(root, path)
+// findClosingDelimiter scans data line by line for a line made up solely of
+// "---" (trailing spaces and a CR are tolerated). It returns the offset where
+// that line starts and the offset just past it, or -1 when there is none.
+func findClosingDelimiter(data []byte) (int, int) {
+	offset := 0
+	for offset < len(data) {
+		line := data[offset:]
+		next := len(data)
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+			next = offset + i + 1
+		}
+		if string(bytes.TrimRight(line, " \t\r")) == "---" {
+			return offset, next
+		}
+		offset = next
+	}
+	return -1, -1
+}
+
+// leadingLines returns up to n lines from the start of data, used to give
+// front matter errors some context.
+func leadingLines(data []byte, n int) string {
+	lines := strings.SplitN(string(data), "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	for i, l := range lines {
+		lines[i] = "  " + strings.TrimRight(l, "\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Values of -dateInPath.
+const (
+	dateInPathYear  = "year"
+	dateInPathMonth = "month"
+)
+
+// datePrefix returns the path prefix -dateInPath adds in front of a post's
+// slug, such as "2024/" or "2024/05/". Undated posts get none.
+func datePrefix(mode string, date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	switch mode {
+	case dateInPathYear:
+		return date.Format("2006") + "/"
+	case dateInPathMonth:
+		return date.Format("2006/01") + "/"
+	}
+	return ""
+}
+
+// buildSlug derives a post's URL path from its source file. The result always
+// uses forward slashes so generated links are identical on every OS; convert
+// it with filepath.FromSlash before touching the disk.
+func buildSlug(root, file string) string {
+	rel, err := filepath.Rel(root, file)
 	if err != nil {
-		rel = path
+		rel = file
 	}
-	rel = strings.TrimSuffix(rel, filepath.Ext(rel))
-	rel = strings.ToLower(rel)
-	return strings.ReplaceAll(rel, string(filepath.Separator), "/")
+	rel = filepath.ToSlash(rel)
+	rel = strings.TrimSuffix(rel, path.Ext(rel))
+	return strings.ToLower(path.Clean(rel))
 }
 
+// tagSlug turns a tag name into a URL path segment. Hangul is romanized so
+// Korean tags get readable ASCII slugs; other letters are kept as-is.
+// Different tags can romanize alike (갑 and 값 are both "gap"); the slug a
+// tag page actually uses is the one buildTagGroups assigns.
 func tagSlug(name string) string {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return "tag"
-	}
+	return slugify(romanizeHangul(strings.ToLower(strings.TrimSpace(name))))
+}
+
+// tagKey identifies a tag: names with the same key are the same tag. It
+// follows the slug rules without romanization, which is also the slug tag
+// pages used before Hangul was romanized.
+func tagKey(name string) string {
+	return slugify(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// slugify keeps letters and digits of s, lowercased, and turns everything
+// else into single dashes. An empty result becomes "tag".
+func slugify(s string) string {
 	var b strings.Builder
 	lastDash := false
-	for _, r := range strings.ToLower(name) {
+	for _, r := range s {
 		switch {
 		case r >= 'a' && r <= 'z':
 			b.WriteRune(r)
@@ -609,47 +2078,103 @@ func tagSlug(name string) string {
 }
 
 func tagURL(name string) string {
-	return "/tags/" + tagSlug(name) + "/"
+	return tagPath(tagSlug(name))
+}
+
+func tagPath(slug string) string {
+	return "/tags/" + slug + "/"
+}
+
+// tagSlugs maps tag keys to the slugs buildTagGroups assigned, so links to
+// a tag agree with its page when its slug had to be disambiguated.
+type tagSlugs map[string]string
+
+func (s tagSlugs) add(groups []tagGroup) {
+	for _, g := range groups {
+		s[g.key] = g.Slug
+	}
+}
+
+// url returns the page URL of the tag name, falling back to tagURL for
+// tags without a page.
+func (s tagSlugs) url(name string) string {
+	if slug, ok := s[tagKey(name)]; ok {
+		return tagPath(slug)
+	}
+	return tagURL(name)
+}
+
+// copyStats counts the asset files a build copied.
+type copyStats struct {
+	Files int
+	Bytes int64
 }
 
-func copyAssets(srcDir, dstDir string) error {
+func (c *copyStats) add(n int64) {
+	c.Files++
+	c.Bytes += n
+}
+
+// copyAssets copies every file under srcDir to dstDir in sorted path order
+// so logs and anything derived from the copy are reproducible.
+func copyAssets(out *buildOutput, srcDir, dstDir string) (copyStats, error) {
+	var stats copyStats
 	if _, err := os.Stat(srcDir); errors.Is(err, fs.ErrNotExist) {
-		return nil
+		return stats, nil
 	}
-	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+	var files []string
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
 			return err
 		}
 		rel, err := filepath.Rel(srcDir, path)
 		if err != nil {
 			return err
 		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	sort.Strings(files)
+	if err := ensureDir(dstDir); err != nil {
+		return stats, err
+	}
+	for _, rel := range files {
 		target := filepath.Join(dstDir, rel)
-		if d.IsDir() {
-			return ensureDir(target)
-		}
 		if err := ensureDir(filepath.Dir(target)); err != nil {
-			return err
+			return stats, err
 		}
-		return copyFile(path, target)
-	})
+		n, err := out.copyFile(filepath.Join(srcDir, rel), target)
+		if err != nil {
+			return stats, err
+		}
+		stats.add(n)
+	}
+	return stats, nil
 }
 
-func copyFile(src, dst string) error {
+func (o *buildOutput) copyFile(src, dst string) (int64, error) {
 	in, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("open asset %s: %w", src, err)
+		return 0, fmt.Errorf("open asset %s: %w", src, err)
 	}
 	defer in.Close()
 	out, err := os.Create(dst)
 	if err != nil {
-		return fmt.Errorf("create asset %s: %w", dst, err)
+		return 0, fmt.Errorf("create asset %s: %w", dst, err)
 	}
 	defer out.Close()
-	if _, err := io.Copy(out, in); err != nil {
-		return fmt.Errorf("copy asset %s: %w", dst, err)
+	n, err := io.Copy(out, in)
+	if err != nil {
+		return n, fmt.Errorf("copy asset %s: %w", dst, err)
 	}
-	return out.Close()
+	if err := out.Close(); err != nil {
+		return n, err
+	}
+	o.record(dst)
+	return n, nil
 }
 
 func pickTitle(fm frontMatter, slug string) string {
@@ -659,6 +2184,17 @@ func pickTitle(fm frontMatter, slug string) string {
 	return strings.Title(strings.ReplaceAll(filepath.Base(slug), "-", " "))
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
@@ -668,10 +2204,42 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
-func formatDate(t time.Time) string {
-	return t.Format("2006-01-02")
+// formatDate formats the calendar date of t in loc as YYYY-MM-DD. Front
+// matter dates are already in -timezone; converting again here covers git
+// dates and other times that are not.
+func formatDate(t time.Time, loc *time.Location) string {
+	return inZone(t, loc).Format("2006-01-02")
+}
+
+// ogLocales maps bare language codes to the territory Open Graph expects.
+var ogLocales = map[string]string{
+	"ko": "ko_KR",
+	"en": "en_US",
+	"ja": "ja_JP",
+	"zh": "zh_CN",
+	"de": "de_DE",
+	"fr": "fr_FR",
+	"es": "es_ES",
+}
+
+// ogLocale turns a language tag such as "ko" or "en-GB" into an og:locale
+// value ("ko_KR", "en_GB"). Unknown bare codes are returned unchanged.
+func ogLocale(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if base, region, ok := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); ok {
+		return strings.ToLower(base) + "_" + strings.ToUpper(region)
+	}
+	if locale, ok := ogLocales[strings.ToLower(lang)]; ok {
+		return locale
+	}
+	return lang
 }
 
-func formatRFC1123(t time.Time) string {
-	return t.UTC().Format(time.RFC1123Z)
+// formatRFC1123 formats t in loc; RFC1123Z carries the numeric offset so the
+// instant is unchanged while the weekday and date match the site's zone.
+func formatRFC1123(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(time.RFC1123Z)
 }
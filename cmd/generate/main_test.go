@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		title   string
+		summary string
+		body    string
+	}{
+		{
+			name:  "horizontal rule as first body line",
+			src:   "---\ntitle: Rule\ndate: 2024-05-03\n---\n---\n\ntext\n",
+			title: "Rule",
+			body:  "---\n\ntext\n",
+		},
+		{
+			name:    "multiline string with a dash line",
+			src:     "---\ntitle: Dashes\ndate: 2024-05-03\nsummary: |\n  before\n  ---\n  after\n---\nbody\n",
+			title:   "Dashes",
+			summary: "before\n---\nafter\n",
+			body:    "body\n",
+		},
+		{
+			name:  "CRLF line endings",
+			src:   "---\r\ntitle: Windows\r\ndate: 2024-05-03\r\n---\r\nbody\r\n",
+			title: "Windows",
+			body:  "body\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := splitFrontMatter([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("splitFrontMatter: %v", err)
+			}
+			if fm.Title != tt.title {
+				t.Errorf("title = %q, want %q", fm.Title, tt.title)
+			}
+			if fm.Summary != tt.summary {
+				t.Errorf("summary = %q, want %q", fm.Summary, tt.summary)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatterUnterminated(t *testing.T) {
+	_, _, err := splitFrontMatter([]byte("---\ntitle: Open\ndate: 2024-05-03\nbody\n"))
+	if err == nil || !strings.Contains(err.Error(), "unterminated front matter") {
+		t.Fatalf("err = %v, want unterminated front matter", err)
+	}
+}
+
+func TestSplitFrontMatterFixtures(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		title string
+		body  string
+	}{
+		{
+			name:  "CRLF with CRLF body lines",
+			src:   "---\r\ntitle: Windows\r\ndate: 2024-05-03\r\n---\r\n\r\nfirst\r\nsecond\r\n",
+			title: "Windows",
+			body:  "first\r\nsecond\r\n",
+		},
+		{
+			name:  "leading blank lines",
+			src:   "\n\n  \n---\ntitle: Blank\ndate: 2024-05-03\n---\nbody\n",
+			title: "Blank",
+			body:  "body\n",
+		},
+		{
+			name:  "closing fence at EOF",
+			src:   "---\ntitle: End\ndate: 2024-05-03\n---",
+			title: "End",
+		},
+		{
+			name:  "closing fence and newline at EOF",
+			src:   "---\r\ntitle: End\r\ndate: 2024-05-03\r\n---\r\n",
+			title: "End",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := splitFrontMatter([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("splitFrontMatter: %v", err)
+			}
+			if fm.Title != tt.title {
+				t.Errorf("title = %q, want %q", fm.Title, tt.title)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestLeadingLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		n    int
+		want string
+	}{
+		{"CRLF", "a\r\nb\r\nc\r\n", 2, "  a\n  b"},
+		{"fewer lines than n", "only", 3, "  only"},
+		{"leading blank line", "\nx\n", 2, "  \n  x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leadingLines([]byte(tt.data), tt.n); got != tt.want {
+				t.Errorf("leadingLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileModeIsPerBuild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not POSIX modes on Windows")
+	}
+	private := testConfig(t)
+	private.fileMode = 0o600
+	writeContent(t, private, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, private)
+
+	public := testConfig(t)
+	writeContent(t, public, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, public)
+
+	for _, tt := range []struct {
+		cfg  config
+		want os.FileMode
+	}{{private, 0o600}, {public, 0o644}} {
+		info, err := os.Stat(filepath.Join(tt.cfg.outputDir, "hello", "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != tt.want {
+			t.Errorf("%s mode = %v, want %v", tt.cfg.outputDir, got, tt.want)
+		}
+	}
+}
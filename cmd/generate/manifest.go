@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const manifestURL = "/site.webmanifest"
+
+// webManifest is both the manifest.yaml config and the JSON written to
+// site.webmanifest.
+type webManifest struct {
+	Name            string         `yaml:"name" json:"name"`
+	ShortName       string         `yaml:"short_name" json:"short_name,omitempty"`
+	StartURL        string         `yaml:"start_url" json:"start_url"`
+	Display         string         `yaml:"display" json:"display"`
+	ThemeColor      string         `yaml:"theme_color" json:"theme_color,omitempty"`
+	BackgroundColor string         `yaml:"background_color" json:"background_color,omitempty"`
+	Icons           []manifestIcon `yaml:"icons" json:"icons"`
+}
+
+type manifestIcon struct {
+	Src     string `yaml:"src" json:"src"`
+	Sizes   string `yaml:"sizes" json:"sizes,omitempty"`
+	Type    string `yaml:"type" json:"type,omitempty"`
+	Purpose string `yaml:"purpose" json:"purpose,omitempty"`
+}
+
+// loadManifest reads the manifest config. A missing file returns nil, which
+// turns the feature off entirely.
+func loadManifest(file string) (*webManifest, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", file, err)
+	}
+	var m webManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", file, err)
+	}
+	if m.Name == "" {
+		return nil, fmt.Errorf("manifest %s: name is required", file)
+	}
+	if m.StartURL == "" {
+		m.StartURL = "/"
+	}
+	if m.Display == "" {
+		m.Display = "standalone"
+	}
+	if m.Icons == nil {
+		m.Icons = []manifestIcon{}
+	}
+	return &m, nil
+}
+
+// renderManifest writes site.webmanifest and warns about icons missing from
+// the output. It must run after assets have been copied.
+func renderManifest(cfg config) error {
+	for _, icon := range cfg.manifest.Icons {
+		if !strings.HasPrefix(icon.Src, "/") || strings.HasPrefix(icon.Src, "//") {
+			continue
+		}
+		target := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(icon.Src, "/")))
+		if _, err := os.Stat(target); err != nil {
+			cfg.logger.Warn(fmt.Sprintf("매니페스트 아이콘 %s 파일이 없습니다.", icon.Src))
+		}
+	}
+	return cfg.out.writeJSON(filepath.Join(cfg.outputDir, strings.TrimPrefix(manifestURL, "/")), cfg.manifest)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// contentMount is one -content entry: a directory whose posts are published
+// under Prefix and, when Repo is set, edited in that GitHub repository
+// instead of -repo.
+type contentMount struct {
+	Dir    string
+	Prefix string
+	Repo   string
+}
+
+// parseContentMounts reads -content entries of the form dir, dir=prefix or
+// dir=prefix@owner/repo, e.g. "content,../til=til@me/til". It returns the
+// directories in order and the mounts keyed by directory.
+func parseContentMounts(entries []string) ([]string, map[string]contentMount, error) {
+	dirs := make([]string, 0, len(entries))
+	mounts := make(map[string]contentMount, len(entries))
+	prefixes := make(map[string]string)
+	for _, entry := range entries {
+		dir, rest, _ := strings.Cut(entry, "=")
+		prefix, repo, _ := strings.Cut(rest, "@")
+		m := contentMount{
+			Dir:    filepath.Clean(strings.TrimSpace(dir)),
+			Prefix: strings.Trim(path.Clean("/"+strings.TrimSpace(prefix)), "/"),
+			Repo:   strings.TrimSpace(repo),
+		}
+		if _, dup := mounts[m.Dir]; dup {
+			return nil, nil, fmt.Errorf("content directory %s is mounted twice", m.Dir)
+		}
+		if repo != "" && strings.Count(m.Repo, "/") != 1 {
+			return nil, nil, fmt.Errorf("content %s: repo %q: want owner/name", m.Dir, m.Repo)
+		}
+		if m.Prefix != "" {
+			if other, ok := prefixes[m.Prefix]; ok {
+				return nil, nil, fmt.Errorf("content %s and %s share the prefix %q", other, m.Dir, m.Prefix)
+			}
+			prefixes[m.Prefix] = m.Dir
+		}
+		dirs = append(dirs, m.Dir)
+		mounts[m.Dir] = m
+	}
+	return dirs, mounts, nil
+}
+
+// mountRepo returns the GitHub repository posts under root are edited in.
+func mountRepo(cfg config, root string) string {
+	return firstNonEmpty(cfg.mounts[root].Repo, cfg.githubRepo)
+}
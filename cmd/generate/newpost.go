@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runNew implements `generate new "Post Title"`: it scaffolds a draft post
+// named <date>-<slug>.md in the content directory and prints its path.
+func runNew(args []string) error {
+	fset := flag.NewFlagSet("new", flag.ContinueOnError)
+	contentDir := fset.String("content", "content", "Content directory to create the post in")
+	timezone := fset.String("timezone", "UTC", "IANA time zone used for the post date (e.g. Asia/Seoul)")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), `usage: generate new [-content dir] [-timezone zone] "Post Title"`)
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	title := strings.TrimSpace(strings.Join(fset.Args(), " "))
+	if title == "" {
+		fset.Usage()
+		return errors.New("new: a post title is required")
+	}
+
+	loc, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return fmt.Errorf("new: invalid -timezone %q: %w", *timezone, err)
+	}
+	now, err := buildTime()
+	if err != nil {
+		return err
+	}
+	now = now.In(loc).Truncate(time.Minute)
+
+	meta, err := yaml.Marshal(frontMatter{
+		Title: title,
+		Date:  now,
+		Tags:  []string{},
+		Draft: true,
+	})
+	if err != nil {
+		return fmt.Errorf("new: encode front matter: %w", err)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(meta)
+	buf.WriteString("---\n\n")
+
+	if err := ensureDir(*contentDir); err != nil {
+		return err
+	}
+	name := now.Format("2006-01-02") + "-" + tagSlug(title) + ".md"
+	target := filepath.Join(*contentDir, name)
+	fh, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("new: %s already exists", target)
+	}
+	if err != nil {
+		return fmt.Errorf("new: create %s: %w", target, err)
+	}
+	if _, err := fh.Write(buf.Bytes()); err != nil {
+		fh.Close()
+		return fmt.Errorf("new: write %s: %w", target, err)
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	fmt.Println(target)
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultNewsletterTemplate is a standalone email document: a single centred
+// table, inline styles only and no external stylesheet, which is what most
+// email clients support.
+const defaultNewsletterTemplate = `<!DOCTYPE html>
+<html lang="{{ .Language }}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{ .Title }}</title>
+</head>
+<body style="margin:0;padding:0;background:#f4f4f4;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="background:#f4f4f4;">
+<tr><td align="center" style="padding:24px 12px;">
+<table role="presentation" width="600" cellpadding="0" cellspacing="0" border="0" style="max-width:600px;width:100%;background:#ffffff;font-family:-apple-system,'Apple SD Gothic Neo','Malgun Gothic',sans-serif;font-size:16px;line-height:1.7;color:#222222;">
+<tr><td style="padding:24px 32px;border-bottom:1px solid #eeeeee;">
+<h1 style="margin:0;font-size:24px;"><a href="{{ .SiteURL }}" style="color:#222222;text-decoration:none;">{{ .Title }}</a></h1>
+</td></tr>
+{{ range .Posts }}
+<tr><td style="padding:24px 32px;border-bottom:1px solid #eeeeee;">
+<h2 style="margin:0 0 4px;font-size:20px;"><a href="{{ .URL }}" style="color:#1a5fb4;text-decoration:none;">{{ .Title }}</a></h2>
+<p style="margin:0 0 16px;color:#777777;font-size:13px;">{{ formatDate .Date }}</p>
+{{ .HTML }}
+<p style="margin:16px 0 0;"><a href="{{ .URL }}" style="color:#1a5fb4;">{{ label "readOnBlog" }}</a></p>
+</td></tr>
+{{ end }}
+<tr><td style="padding:16px 32px;color:#999999;font-size:12px;">
+<a href="{{ .SiteURL }}" style="color:#999999;">{{ .SiteURL }}</a>
+</td></tr>
+</table>
+</td></tr>
+</table>
+</body>
+</html>
+`
+
+// newsletterPost is one post as the email template sees it.
+type newsletterPost struct {
+	Title string
+	Date  time.Time
+	URL   string
+	HTML  template.HTML
+}
+
+var (
+	urlAttrPattern = regexp.MustCompile(`(?i)(\s(?:href|src)\s*=\s*)(["'])([^"']*)(["'])`)
+	emailTagStyles = map[string]string{
+		"img":        "max-width:100%;height:auto;border:0;",
+		"pre":        "background:#f6f8fa;padding:12px;overflow:auto;font-size:13px;line-height:1.5;",
+		"blockquote": "margin:0;padding:0 16px;border-left:4px solid #dddddd;color:#555555;",
+		"table":      "border-collapse:collapse;",
+		"th":         "border:1px solid #dddddd;padding:4px 8px;",
+		"td":         "border:1px solid #dddddd;padding:4px 8px;",
+	}
+	emailTagPattern = regexp.MustCompile(`(?i)<(img|pre|blockquote|table|th|td)\b`)
+)
+
+// runNewsletter implements `generate newsletter`: it renders the most recent
+// posts into one standalone, email-friendly HTML file. It reads content only
+// and never touches the rest of the site build.
+func runNewsletter(args []string) error {
+	cfg := config{
+		logger:      slog.Default(),
+		out:         newBuildOutput(0o644),
+		draftPrefix: "_",
+		csvHeader:   true,
+		location:    time.UTC,
+	}
+	fset := flag.NewFlagSet("newsletter", flag.ContinueOnError)
+	contentDirs := fset.String("content", "content", "Comma-separated list of markdown content directories")
+	extensions := fset.String("extensions", ".md,.markdown", "Comma-separated content file extensions")
+	fset.StringVar(&cfg.baseURL, "baseURL", "", "Base URL every link and image is made absolute against (required)")
+	fset.StringVar(&cfg.title, "title", defaultTitle, "Newsletter heading")
+	fset.StringVar(&cfg.language, "language", "ko", "Language of the document")
+	count := fset.Int("count", 5, "Number of most recent posts to include")
+	since := fset.String("since", "", "Include every post dated on or after this day (YYYY-MM-DD) instead of -count")
+	out := fset.String("out", filepath.Join("public", "newsletter.html"), "File to write")
+	tplFile := fset.String("template", "", "Email template to use instead of the built-in one")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: generate newsletter -baseURL url [flags]")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if err := applyEnvFlags(fset, map[string]string{"baseURL": envFlags["baseURL"], "title": envFlags["title"]}); err != nil {
+		return fmt.Errorf("newsletter: %w", err)
+	}
+	if strings.TrimSpace(cfg.baseURL) == "" {
+		fset.Usage()
+		return errors.New("newsletter: -baseURL is required")
+	}
+	baseURL, err := normalizeBaseURL("baseURL", cfg.baseURL)
+	if err != nil {
+		return fmt.Errorf("newsletter: %w", err)
+	}
+	cfg.baseURL = baseURL
+	if cfg.contentDirs, cfg.mounts, err = parseContentMounts(splitList(*contentDirs)); err != nil {
+		return fmt.Errorf("newsletter: %w", err)
+	}
+	cfg.extensions = splitList(*extensions)
+	if cfg.now, err = buildTime(); err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *since != "" {
+		if from, err = time.Parse(time.DateOnly, *since); err != nil {
+			return fmt.Errorf("newsletter: invalid -since %q: want YYYY-MM-DD", *since)
+		}
+	} else if *count <= 0 {
+		return fmt.Errorf("newsletter: -count must be positive, got %d", *count)
+	}
+
+	tpl := template.New("newsletter").Funcs(template.FuncMap{
+		"formatDate": func(t time.Time) string { return formatDate(t, cfg.location) },
+		"label": func(key string, args ...any) string {
+			return uiLabel(cfg.language, key, args...)
+		},
+	})
+	if *tplFile != "" {
+		tpl, err = tpl.ParseFiles(*tplFile)
+		if err == nil {
+			tpl = tpl.Lookup(filepath.Base(*tplFile))
+		}
+	} else {
+		tpl, err = tpl.Parse(defaultNewsletterTemplate)
+	}
+	if err != nil {
+		return &TemplateError{Name: "newsletter", Path: *tplFile, Err: err}
+	}
+
+	posts, err := loadPosts(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	posts, _ = dropExpired(cfg, posts)
+	sort.Slice(posts, func(i, j int) bool { return posts[i].Date.After(posts[j].Date) })
+
+	var picked []newsletterPost
+	for _, p := range listedPosts(posts) {
+		if p.Draft || p.Date.IsZero() {
+			continue
+		}
+		if *since != "" && p.Date.Before(from) {
+			break
+		}
+		if *since == "" && len(picked) == *count {
+			break
+		}
+		postURL := cfg.baseURL + "/" + p.Slug + "/"
+		picked = append(picked, newsletterPost{
+			Title: p.Title,
+			Date:  p.Date,
+			URL:   postURL,
+			HTML:  template.HTML(emailHTML(string(p.ContentHTML), cfg.baseURL, postURL)),
+		})
+	}
+	if len(picked) == 0 {
+		return errors.New("newsletter: no posts match")
+	}
+
+	data := map[string]any{
+		"Title":    cfg.title,
+		"Language": cfg.language,
+		"SiteURL":  cfg.baseURL + "/",
+		"Posts":    picked,
+	}
+	if err := ensureDir(filepath.Dir(*out)); err != nil {
+		return err
+	}
+	if err := cfg.out.writeHTML(*out, tpl, tpl.Name(), data); err != nil {
+		return &RenderError{Kind: "newsletter", Target: *out, Err: err}
+	}
+	cfg.logger.Info(fmt.Sprintf("게시물 %d개로 뉴스레터 %s를 만들었습니다.", len(picked), *out))
+	return nil
+}
+
+// emailHTML prepares rendered post HTML for email clients: every href and
+// src is made absolute (root-relative paths against baseURL, others against
+// the post's own URL) and common block elements get inline styles.
+func emailHTML(html, baseURL, postURL string) string {
+	base, err := url.Parse(postURL)
+	if err != nil {
+		return html
+	}
+	html = urlAttrPattern.ReplaceAllStringFunc(html, func(attr string) string {
+		m := urlAttrPattern.FindStringSubmatch(attr)
+		return m[1] + m[2] + absoluteURL(base, baseURL, m[3]) + m[4]
+	})
+	return emailTagPattern.ReplaceAllStringFunc(html, func(tag string) string {
+		name := strings.ToLower(tag[1:])
+		return tag + ` style="` + emailTagStyles[name] + `"`
+	})
+}
+
+// absoluteURL resolves ref for use outside the site. Absolute URLs,
+// including mailto: and tel:, are returned unchanged.
+func absoluteURL(base *url.URL, baseURL, ref string) string {
+	if strings.HasPrefix(ref, "//") {
+		return base.Scheme + ":" + ref
+	}
+	if strings.HasPrefix(ref, "/") {
+		return baseURL + ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.IsAbs() {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
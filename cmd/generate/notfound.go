@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// parseSectionNotFound parses every templates/<section>-404.html into the
+// error page for /<section>/404.html.
+func parseSectionNotFound(layout *template.Template, dir string) (map[string]*template.Template, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*-404.html"))
+	if err != nil {
+		return nil, fmt.Errorf("find section 404 templates: %w", err)
+	}
+	var pages map[string]*template.Template
+	for _, path := range paths {
+		section := strings.TrimSuffix(filepath.Base(path), "-404.html")
+		if section == "" {
+			continue
+		}
+		tpl, err := parsePage(layout, section+"-404", path)
+		if err != nil {
+			return nil, err
+		}
+		if pages == nil {
+			pages = make(map[string]*template.Template)
+		}
+		pages[section] = tpl
+	}
+	return pages, nil
+}
+
+// renderNotFoundPages writes /404.html from templates/404.html and
+// /<section>/404.html for each section template. Hosts that serve
+// per-directory error pages use the nearest one; a section without its own
+// template falls back to the global page. Nothing is written without
+// templates.
+func renderNotFoundPages(cfg config, tpls *templateBundle) error {
+	if tpls.notFound != nil {
+		if err := writeNotFound(cfg, tpls.notFound, ""); err != nil {
+			return err
+		}
+	}
+	sections := make([]string, 0, len(tpls.sectionNotFound))
+	for section := range tpls.sectionNotFound {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		if err := writeNotFound(cfg, tpls.sectionNotFound[section], section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeNotFound(cfg config, tpl *template.Template, section string) error {
+	dir := filepath.Join(cfg.outputDir, filepath.FromSlash(section))
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, "404.html")
+	data := map[string]any{
+		"Title":   uiLabel(cfg.language, "notFound"),
+		"Section": section,
+		"Site":    newSite(cfg),
+	}
+	if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+		return &RenderError{Kind: "404", Slug: section, Target: target, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// notifyPayload is the JSON body POSTed to -notifyURL after a successful
+// build. The schema is versioned; fields are only ever added.
+//
+//	{
+//	  "version": 1,
+//	  "builtAt": "2024-05-01T12:00:00Z",  // RFC 3339 build time
+//	  "postCount": 42,                    // published posts in the build
+//	  "newPostCount": 1,                  // posts whose page did not exist before
+//	  "newPosts": [{"title": "…", "url": "https://…/slug/", "date": "…"}]
+//	}
+type notifyPayload struct {
+	Version      int          `json:"version"`
+	BuiltAt      string       `json:"builtAt"`
+	PostCount    int          `json:"postCount"`
+	NewPostCount int          `json:"newPostCount"`
+	NewPosts     []notifyPost `json:"newPosts"`
+}
+
+type notifyPost struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Date  string `json:"date,omitempty"`
+}
+
+const (
+	notifyAttempts = 3
+	notifyTimeout  = 10 * time.Second
+	notifyBackoff  = time.Second
+)
+
+// freshPosts returns the posts whose output page does not exist yet. It has
+// to be called before the posts are written.
+func freshPosts(cfg config, posts []post) []post {
+	var out []post
+	for _, p := range posts {
+		target := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug), "index.html")
+		if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func buildNotifyPayload(cfg config, posts, fresh []post) notifyPayload {
+	payload := notifyPayload{
+		Version:      1,
+		BuiltAt:      cfg.now.UTC().Format(time.RFC3339),
+		PostCount:    len(posts),
+		NewPostCount: len(fresh),
+		NewPosts:     make([]notifyPost, 0, len(fresh)),
+	}
+	for _, p := range fresh {
+		np := notifyPost{Title: p.Title, URL: cfg.baseURL + "/" + p.Slug + "/"}
+		if !p.Date.IsZero() {
+			np.Date = p.Date.Format(time.RFC3339)
+		}
+		payload.NewPosts = append(payload.NewPosts, np)
+	}
+	return payload
+}
+
+// notify POSTs payload to url, retrying a couple of times on failure.
+func notify(ctx context.Context, logger *slog.Logger, url string, payload notifyPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode notify payload: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= notifyAttempts; attempt++ {
+		if lastErr = postJSON(ctx, url, body); lastErr == nil {
+			return nil
+		}
+		if attempt < notifyAttempts {
+			logger.Warn(fmt.Sprintf("알림 전송 실패(%d/%d): %v", attempt, notifyAttempts, lastErr))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(notifyBackoff * time.Duration(attempt)):
+			}
+		}
+	}
+	return fmt.Errorf("notify %s: %w", url, lastErr)
+}
+
+func postJSON(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// notifyServer records the payloads POSTed to it. The first fail requests
+// get a 500.
+func notifyServer(t *testing.T, fail int) (*httptest.Server, func() []notifyPayload) {
+	var (
+		mu       sync.Mutex
+		requests int
+		payloads []notifyPayload
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests <= fail {
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		var p notifyPayload
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		payloads = append(payloads, p)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []notifyPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]notifyPayload(nil), payloads...)
+	}
+}
+
+func TestNotifyListsNewPosts(t *testing.T) {
+	srv, payloads := notifyServer(t, 0)
+	cfg := testConfig(t)
+	cfg.notifyURL = srv.URL
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, cfg)
+	writeContent(t, cfg, "second.md", "---\ntitle: Second\ndate: 2024-05-04\n---\nbody\n")
+	writeContent(t, cfg, "draft.md", "---\ntitle: Draft\ndate: 2024-05-05\ndraft: true\n---\nbody\n")
+	buildSite(t, cfg)
+
+	got := payloads()
+	if len(got) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(got))
+	}
+	first, second := got[0], got[1]
+	if first.Version != 1 || first.BuiltAt != "2025-01-01T00:00:00Z" || first.PostCount != 1 || first.NewPostCount != 1 {
+		t.Errorf("first payload = %+v", first)
+	}
+	if len(first.NewPosts) != 1 || first.NewPosts[0] != (notifyPost{Title: "Hello", URL: defaultBaseURL + "/hello/", Date: "2024-05-03T00:00:00Z"}) {
+		t.Errorf("first new posts = %+v, want hello", first.NewPosts)
+	}
+	if second.PostCount != 2 || second.NewPostCount != 1 || len(second.NewPosts) != 1 || second.NewPosts[0].Title != "Second" {
+		t.Errorf("second payload = %+v, want only Second new", second)
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	srv, payloads := notifyServer(t, 1)
+	cfg := testConfig(t)
+	cfg.notifyURL = srv.URL
+	cfg.notifyRequired = true
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	buildSite(t, cfg)
+	if got := payloads(); len(got) != 1 || got[0].NewPostCount != 1 {
+		t.Errorf("payloads after a failed first attempt = %+v, want one", got)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// outputFormat is one file a post can be rendered to inside its directory.
+// The flag-driven exports register here so pages can also turn them on or
+// off individually with the outputs front matter list.
+type outputFormat struct {
+	Name string
+	File string
+	// global reports whether the format is on for every page by flag.
+	global func(cfg config) bool
+	// write renders p; nil for html, which writePost renders with the post
+	// template.
+	write func(cfg config, p post) error
+}
+
+var outputFormats = []outputFormat{
+	{Name: "html", File: "index.html", global: func(config) bool { return true }},
+	{Name: "json", File: "index.json", global: func(cfg config) bool { return cfg.jsonPages }, write: writePageJSON},
+	{Name: "text", File: "index.txt", global: func(cfg config) bool { return cfg.textPages }, write: writeTextPage},
+	{Name: "markdown", File: "index.md", global: func(cfg config) bool { return cfg.exportMarkdown }, write: writeMarkdownSource},
+}
+
+func lookupOutputFormat(name string) (outputFormat, bool) {
+	for _, f := range outputFormats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return outputFormat{}, false
+}
+
+// resolveOutputs returns the formats a page is rendered to. Without an
+// outputs list the flags decide; with one, exactly the listed formats run,
+// plus html unless it is disabled as "-html".
+func resolveOutputs(cfg config, names []string) (map[string]bool, error) {
+	out := make(map[string]bool, len(outputFormats))
+	for _, f := range outputFormats {
+		out[f.Name] = f.global(cfg)
+	}
+	if len(names) == 0 {
+		return out, nil
+	}
+	for name := range out {
+		out[name] = name == "html"
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		on := !strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		if _, ok := lookupOutputFormat(name); !ok {
+			known := make([]string, 0, len(outputFormats))
+			for _, f := range outputFormats {
+				known = append(known, f.Name)
+			}
+			return nil, fmt.Errorf("unknown output format %q (known: %s)", name, strings.Join(known, ", "))
+		}
+		out[name] = on
+	}
+	if out["json"] && cfg.exportJSON {
+		return nil, fmt.Errorf("output format json and -exportJSON both write index.json")
+	}
+	return out, nil
+}
+
+// outputURL is the site path of p's file in format name, or "" when p is
+// not rendered to it.
+func outputURL(p post, name string) string {
+	f, ok := lookupOutputFormat(name)
+	if !ok || !p.outputs[name] {
+		return ""
+	}
+	return "/" + p.Slug + "/" + f.File
+}
+
+// writeOutputs renders p to every non-html format it has enabled.
+func writeOutputs(cfg config, p post) error {
+	for _, f := range outputFormats {
+		if f.write == nil || !p.outputs[f.Name] {
+			continue
+		}
+		if err := f.write(cfg, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
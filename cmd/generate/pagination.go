@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// paginator is one page of a paginated listing (the homepage or a tag),
+// exposed to templates as .Paginator. The "pagination" partial in base.html
+// renders its navigation and the layout turns PrevURL/NextURL into
+// rel=prev/next links.
+type paginator struct {
+	CurrentPage int
+	TotalPages  int
+	HasPrev     bool
+	HasNext     bool
+	PrevURL     string
+	NextURL     string
+	FirstURL    string
+	LastURL     string
+	Pages       []pageLink
+	Posts       []post
+}
+
+// pageLink is one entry of paginator.Pages. Ellipsis entries stand for a
+// run of pages left out of the window and have no number or URL.
+type pageLink struct {
+	Number   int
+	URL      string
+	Current  bool
+	Ellipsis bool
+}
+
+// pageWindow is how many pages either side of the current one are listed
+// before the rest collapse into an ellipsis.
+const pageWindow = 2
+
+// pageLinks lists the first and last page, the pages within pageWindow of
+// current, and an ellipsis for every gap between them.
+func pageLinks(base string, current, total int) []pageLink {
+	var links []pageLink
+	last := 0
+	for n := 1; n <= total; n++ {
+		if n != 1 && n != total && (n < current-pageWindow || n > current+pageWindow) {
+			continue
+		}
+		if last != 0 && n > last+1 {
+			links = append(links, pageLink{Ellipsis: true})
+		}
+		links = append(links, pageLink{Number: n, URL: pageURL(base, n), Current: n == current})
+		last = n
+	}
+	return links
+}
+
+// pageURL returns the site path of page n of the listing at base, which must
+// end in a slash: base itself for the first page, <base>page/<n>/ after that.
+func pageURL(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%spage/%d/", base, n)
+}
+
+// sitePath returns the path part of -baseURL without a trailing slash, e.g.
+// "/blog" for https://x.dev/blog, or "" for a site at the domain root.
+func sitePath(cfg config) string {
+	u, err := url.Parse(feedBase(cfg))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(u.Path, "/")
+}
+
+// relURL prefixes a site-root path such as "/tags/" with sitePath. Page data
+// holds site-root paths, like output paths; templates link them through
+// relURL (or postURL and tagURL) so a site under a -baseURL path works.
+// Absolute, protocol-relative and relative URLs are returned unchanged.
+func relURL(cfg config, u string) string {
+	if !strings.HasPrefix(u, "/") || strings.HasPrefix(u, "//") {
+		return u
+	}
+	return sitePath(cfg) + u
+}
+
+// paginate splits the listing at base into pages of size posts each. A size
+// of 0 or less yields a single page holding everything. There is always at least one
+// page so an empty site still gets a homepage.
+func paginate(base string, posts []post, size int) []paginator {
+	if size <= 0 || len(posts) <= size {
+		return []paginator{{
+			CurrentPage: 1,
+			TotalPages:  1,
+			FirstURL:    base,
+			LastURL:     base,
+			Pages:       pageLinks(base, 1, 1),
+			Posts:       posts,
+		}}
+	}
+	total := (len(posts) + size - 1) / size
+	pages := make([]paginator, 0, total)
+	for n := 1; n <= total; n++ {
+		end := min(n*size, len(posts))
+		p := paginator{
+			CurrentPage: n,
+			TotalPages:  total,
+			HasPrev:     n > 1,
+			HasNext:     n < total,
+			FirstURL:    pageURL(base, 1),
+			LastURL:     pageURL(base, total),
+			Pages:       pageLinks(base, n, total),
+			Posts:       posts[(n-1)*size : end],
+		}
+		if p.HasPrev {
+			p.PrevURL = pageURL(base, n-1)
+		}
+		if p.HasNext {
+			p.NextURL = pageURL(base, n+1)
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaginateUnderBaseURLPath(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		path    string
+	}{
+		{baseURL: "https://x.dev", path: ""},
+		{baseURL: "https://x.dev/blog", path: "/blog"},
+		{baseURL: "https://x.dev/a/b/", path: "/a/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.baseURL = tt.baseURL
+			cfg.pageSize = 1
+			for i := 1; i <= 3; i++ {
+				writeContent(t, cfg, fmt.Sprintf("post-%d.md", i), fmt.Sprintf("---\ntitle: Post %d\ndate: 2024-05-0%d\ntags: [go]\n---\nbody\n", i, i))
+			}
+			buildSite(t, cfg)
+			page := readOutput(t, cfg, "page/2/index.html")
+			for _, link := range []string{"/", "/page/3/", "/post-2/", "/tags/go/", "/tags/", "/assets/style.css"} {
+				if want := `href="` + tt.path + link + `"`; !strings.Contains(page, want) {
+					t.Errorf("page 2 has no %s", want)
+				}
+			}
+			if tt.path != "" && strings.Contains(page, `href="/post-2/"`) {
+				t.Error("page 2 links a post without the base path")
+			}
+		})
+	}
+}
+
+func TestIndexPagesUnderBaseURLPath(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.baseURL = "https://x.dev/blog"
+	cfg.pageSize = 1
+	for i := 1; i <= 3; i++ {
+		writeContent(t, cfg, fmt.Sprintf("post-%d.md", i), fmt.Sprintf("---\ntitle: Post %d\ndate: 2024-05-0%d\n---\nbody\n", i, i))
+	}
+	buildSite(t, cfg)
+	data, err := os.ReadFile(filepath.Join(cfg.outputDir, "page", "2", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`href="/blog/"`, `href="/blog/page/3/"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("page 2 has no %s", want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name    string
+		posts   int
+		current int
+		total   int
+		prev    string
+		next    string
+		// links lists the page numbers of Pages, 0 for an ellipsis.
+		links []int
+	}{
+		{name: "one page", posts: 3, current: 1, total: 1, links: []int{1}},
+		{name: "two pages, first", posts: 15, current: 1, total: 2, next: "/page/2/", links: []int{1, 2}},
+		{name: "two pages, last", posts: 15, current: 2, total: 2, prev: "/", links: []int{1, 2}},
+		{name: "fifty pages, first", posts: 500, current: 1, total: 50, next: "/page/2/", links: []int{1, 2, 3, 0, 50}},
+		{name: "fifty pages, middle", posts: 500, current: 25, total: 50, prev: "/page/24/", next: "/page/26/", links: []int{1, 0, 23, 24, 25, 26, 27, 0, 50}},
+		{name: "fifty pages, near start", posts: 500, current: 4, total: 50, prev: "/page/3/", next: "/page/5/", links: []int{1, 2, 3, 4, 5, 6, 0, 50}},
+		{name: "fifty pages, last", posts: 500, current: 50, total: 50, prev: "/page/49/", links: []int{1, 0, 48, 49, 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := paginate("/", make([]post, tt.posts), 10)
+			if len(pages) != tt.total {
+				t.Fatalf("got %d pages, want %d", len(pages), tt.total)
+			}
+			p := pages[tt.current-1]
+			if p.TotalPages != tt.total || p.CurrentPage != tt.current {
+				t.Errorf("page %d/%d, want %d/%d", p.CurrentPage, p.TotalPages, tt.current, tt.total)
+			}
+			if p.PrevURL != tt.prev || p.HasPrev != (tt.prev != "") {
+				t.Errorf("PrevURL = %q (HasPrev %v), want %q", p.PrevURL, p.HasPrev, tt.prev)
+			}
+			if p.NextURL != tt.next || p.HasNext != (tt.next != "") {
+				t.Errorf("NextURL = %q (HasNext %v), want %q", p.NextURL, p.HasNext, tt.next)
+			}
+			var links []int
+			for _, l := range p.Pages {
+				links = append(links, l.Number)
+				if l.Current != (l.Number == tt.current) {
+					t.Errorf("link %d Current = %v", l.Number, l.Current)
+				}
+			}
+			if fmt.Sprint(links) != fmt.Sprint(tt.links) {
+				t.Errorf("links = %v, want %v", links, tt.links)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+type plainTextOptions struct {
+	includeCode   bool
+	includeImages bool
+}
+
+// plainText flattens a parsed markdown document into reading-order text.
+// Each heading, paragraph, list item and table row becomes its own block and
+// blocks are separated by a blank line. Code blocks and image alt text are
+// skipped unless opts asks for them.
+func plainText(doc ast.Node, src []byte, opts plainTextOptions) string {
+	var blocks []string
+	collectTextBlocks(doc, src, opts, &blocks)
+	return strings.Join(blocks, "\n\n")
+}
+
+func collectTextBlocks(n ast.Node, src []byte, opts plainTextOptions, blocks *[]string) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Heading, *ast.Paragraph, *ast.TextBlock:
+			if text := inlineText(c, src, opts); text != "" {
+				*blocks = append(*blocks, text)
+			}
+		case *east.TableHeader, *east.TableRow:
+			var cells []string
+			for cell := c.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, inlineText(cell, src, opts))
+			}
+			*blocks = append(*blocks, strings.Join(cells, ", "))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if opts.includeCode {
+				if code := strings.TrimRight(string(blockLines(c, src)), "\n"); code != "" {
+					*blocks = append(*blocks, code)
+				}
+			}
+		case *ast.HTMLBlock, *ast.ThematicBreak:
+		default:
+			collectTextBlocks(c, src, opts, blocks)
+		}
+	}
+}
+
+func inlineText(n ast.Node, src []byte, opts plainTextOptions) string {
+	var b strings.Builder
+	writeInlineText(&b, n, src, opts)
+	lines := strings.Split(b.String(), "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func writeInlineText(b *strings.Builder, n ast.Node, src []byte, opts plainTextOptions) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(src))
+			switch {
+			case c.HardLineBreak():
+				b.WriteByte('\n')
+			case c.SoftLineBreak():
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.AutoLink:
+			b.Write(c.Label(src))
+		case *ast.Image:
+			if opts.includeImages {
+				writeInlineText(b, c, src, opts)
+			}
+		case *ast.RawHTML:
+		default:
+			writeInlineText(b, c, src, opts)
+		}
+	}
+}
+
+func blockLines(n ast.Node, src []byte) []byte {
+	var buf []byte
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		buf = append(buf, seg.Value(src)...)
+	}
+	return buf
+}
+
+func writePlainText(cfg config, post post) error {
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(post.Slug), "content.txt")
+	if err := cfg.out.writeText(target, []byte(post.PlainText)); err != nil {
+		return fmt.Errorf("write plain text %s: %w", target, err)
+	}
+	return nil
+}
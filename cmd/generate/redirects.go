@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hostNetlify selects the _redirects/_headers file format used by Netlify and
+// Cloudflare Pages.
+const hostNetlify = "netlify"
+
+// aliasPageTemplate is the meta-refresh page written at each alias path and
+// redirect_to post when no host-specific redirect file is generated.
+var aliasPageTemplate = template.Must(template.New("alias").Parse(`<!DOCTYPE html>
+<html lang="{{ .Lang }}">
+<head>
+<meta charset="utf-8">
+<title>{{ .URL }}</title>
+<link rel="canonical" href="{{ .URL }}">
+<meta name="robots" content="noindex">
+<meta http-equiv="refresh" content="0; url={{ .URL }}">
+</head>
+<body>
+<p>{{ .Before }}<a href="{{ .URL }}">{{ .URL }}</a>{{ .After }}</p>
+</body>
+</html>
+`))
+
+// aliasPageData is the data of aliasPageTemplate for a redirect to url.
+func aliasPageData(cfg config, url string) map[string]string {
+	return map[string]string{
+		"URL":    url,
+		"Lang":   cfg.language,
+		"Before": uiLabel(cfg.language, "redirectBefore"),
+		"After":  uiLabel(cfg.language, "redirectAfter"),
+	}
+}
+
+// redirectRule is one entry of redirects.yaml or a rule derived from an alias.
+type redirectRule struct {
+	From   string `yaml:"from"`
+	To     string `yaml:"to"`
+	Status int    `yaml:"status"`
+}
+
+// aliasSlug normalizes an alias from front matter into slug form, the same
+// shape as post.Slug: no leading or trailing slash.
+func aliasSlug(alias string) string {
+	alias = path.Clean("/" + strings.TrimSpace(alias))
+	return strings.Trim(alias, "/")
+}
+
+// aliasRules returns a 301 rule for every alias of a non-draft post. Aliases
+// of a post with redirect_to go straight to its external target.
+func aliasRules(posts []post) []redirectRule {
+	var rules []redirectRule
+	for _, p := range posts {
+		if p.Draft {
+			continue
+		}
+		to := firstNonEmpty(p.RedirectTo, "/"+p.Slug+"/")
+		for _, a := range p.Aliases {
+			slug := aliasSlug(a)
+			if slug == "" {
+				continue
+			}
+			rules = append(rules, redirectRule{From: "/" + slug + "/", To: to, Status: 301})
+		}
+	}
+	return rules
+}
+
+// tagRedirectRules returns a 301 rule from the URL a tag page had before
+// Hangul in tag slugs was romanized to the one it has now.
+func tagRedirectRules(tags []tagGroup) []redirectRule {
+	inUse := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		inUse[t.Slug] = true
+	}
+	var rules []redirectRule
+	for _, t := range tags {
+		if t.key == t.Slug || inUse[t.key] {
+			continue
+		}
+		rules = append(rules, redirectRule{From: tagPath(t.key), To: tagPath(t.Slug), Status: 301})
+	}
+	return rules
+}
+
+// externalRedirectRules returns a 301 rule from each redirect_to post's own
+// path to its target.
+func externalRedirectRules(posts []post) []redirectRule {
+	var rules []redirectRule
+	for _, p := range posts {
+		if p.RedirectTo != "" {
+			rules = append(rules, redirectRule{From: "/" + p.Slug + "/", To: p.RedirectTo, Status: 301})
+		}
+	}
+	return rules
+}
+
+// redirectTarget validates a redirect_to value, which must be an absolute
+// http or https URL.
+func redirectTarget(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("redirect_to %q: want an absolute http or https URL", raw)
+	}
+	return u.String(), nil
+}
+
+// writeRedirectPage writes the page of a redirect_to post: a meta-refresh
+// document, or nothing when a host redirect file carries the rule instead.
+func writeRedirectPage(cfg config, p post) error {
+	if cfg.redirectsFormat == hostNetlify {
+		return nil
+	}
+	dir := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug))
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, "index.html")
+	data := aliasPageData(cfg, p.RedirectTo)
+	if err := cfg.out.writeHTML(target, aliasPageTemplate, aliasPageTemplate.Name(), data); err != nil {
+		return &RenderError{Kind: "redirect", Slug: p.Slug, Target: target, Err: err}
+	}
+	return nil
+}
+
+// renderAliasPages writes a meta-refresh page at every alias path and old
+// tag URL.
+func renderAliasPages(cfg config, posts []post, tags []tagGroup) error {
+	for _, rule := range append(aliasRules(posts), tagRedirectRules(tags)...) {
+		dir := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.Trim(rule.From, "/")))
+		if err := ensureDir(dir); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, "index.html")
+		to := rule.To
+		if strings.HasPrefix(to, "/") {
+			to = cfg.baseURL + to
+		}
+		data := aliasPageData(cfg, to)
+		if err := cfg.out.writeHTML(target, aliasPageTemplate, aliasPageTemplate.Name(), data); err != nil {
+			return &RenderError{Kind: "alias", Slug: rule.From, Target: target, Err: err}
+		}
+	}
+	return nil
+}
+
+// loadRedirectRules reads custom rules from file. A missing file is not an
+// error; rules without a status default to 301.
+func loadRedirectRules(file string) ([]redirectRule, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read redirects %s: %w", file, err)
+	}
+	var rules []redirectRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse redirects %s: %w", file, err)
+	}
+	for i, r := range rules {
+		if r.From == "" || r.To == "" {
+			return nil, fmt.Errorf("redirects %s: rule %d needs both from and to", file, i+1)
+		}
+		if r.Status == 0 {
+			rules[i].Status = 301
+		}
+	}
+	return rules, nil
+}
+
+// renderNetlifyRedirects writes a Netlify _redirects file from post aliases,
+// old tag URLs, redirect_to posts and custom rules. Identical rules are merged; two rules
+// sending the same path to different places, or a local target that was not
+// generated, fail the build. It must run after every page has been written.
+func renderNetlifyRedirects(cfg config, posts []post, tags []tagGroup) error {
+	custom, err := loadRedirectRules(cfg.redirectsFile)
+	if err != nil {
+		return err
+	}
+
+	byFrom := make(map[string]redirectRule)
+	var order []string
+	rules := append(aliasRules(posts), tagRedirectRules(tags)...)
+	rules = append(rules, externalRedirectRules(posts)...)
+	for _, r := range append(rules, custom...) {
+		if prev, ok := byFrom[r.From]; ok {
+			if prev != r {
+				return fmt.Errorf("conflicting redirects for %s: %s and %s", r.From, prev.To, r.To)
+			}
+			continue
+		}
+		if err := checkRedirectTarget(cfg, r.To); err != nil {
+			return fmt.Errorf("redirect %s: %w", r.From, err)
+		}
+		byFrom[r.From] = r
+		order = append(order, r.From)
+	}
+	sort.Strings(order)
+
+	var b strings.Builder
+	for _, from := range order {
+		r := byFrom[from]
+		fmt.Fprintf(&b, "%s %s %d\n", r.From, r.To, r.Status)
+	}
+	target := filepath.Join(cfg.outputDir, "_redirects")
+	if err := cfg.out.writeText(target, []byte(b.String())); err != nil {
+		return fmt.Errorf("write redirects: %w", err)
+	}
+	return nil
+}
+
+// checkRedirectTarget verifies that a site-relative target was generated by
+// this build; pages left in the output directory by earlier builds do not count. Absolute
+// URLs are accepted as-is.
+func checkRedirectTarget(cfg config, to string) error {
+	if !strings.HasPrefix(to, "/") || strings.HasPrefix(to, "//") {
+		return nil
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(strings.SplitN(to, "#", 2)[0], "/"))
+	candidate := filepath.Join(cfg.outputDir, rel)
+	if strings.HasSuffix(to, "/") || rel == "" {
+		candidate = filepath.Join(candidate, "index.html")
+	}
+	if !cfg.out.wasBuilt(candidate) {
+		return fmt.Errorf("target %s was not generated", to)
+	}
+	return nil
+}
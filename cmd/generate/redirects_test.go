@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNetlifyAliasOfDraftEmitsNoRule(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.redirectsFormat = hostNetlify
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\naliases: [/hi/]\n---\nbody\n")
+	writeContent(t, cfg, "secret.md", "---\ntitle: Secret\ndate: 2024-05-04\ndraft: true\naliases: [/old-secret/]\n---\nbody\n")
+	buildSite(t, cfg)
+
+	got := readOutput(t, cfg, "_redirects")
+	if got != "/hi/ /hello/ 301\n" {
+		t.Errorf("_redirects = %q, want only the alias of hello", got)
+	}
+}
+
+func TestNetlifyCustomRuleToDraftFails(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.redirectsFormat = hostNetlify
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\naliases: [/hi/]\n---\nbody\n")
+	writeContent(t, cfg, "secret.md", "---\ntitle: Secret\ndate: 2024-05-04\ndraft: true\n---\nbody\n")
+	if err := os.WriteFile(cfg.redirectsFile, []byte("- from: /legacy/\n  to: /secret/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A page left by an earlier build, when secret was still published.
+	stale := filepath.Join(cfg.outputDir, "secret", "index.html")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("<p>old</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "target /secret/ was not generated") {
+		t.Fatalf("run error = %v, want target /secret/ was not generated", err)
+	}
+
+	if err := os.Remove(cfg.redirectsFile); err != nil {
+		t.Fatal(err)
+	}
+	buildSite(t, cfg)
+	if got := readOutput(t, cfg, "_redirects"); !strings.Contains(got, "/hi/ /hello/ 301") {
+		t.Errorf("_redirects = %q, want the alias of hello", got)
+	}
+}
+
+func TestNetlifyRedirectTargetFromEarlierRun(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.redirectsFormat = hostNetlify
+	writeContent(t, cfg, "hello.md", "---\ntitle: Hello\ndate: 2024-05-03\n---\nbody\n")
+	writeContent(t, cfg, "secret.md", "---\ntitle: Secret\ndate: 2024-05-04\n---\nbody\n")
+	buildSite(t, cfg)
+
+	// The same process builds again after secret became a draft; the page
+	// written by the first run must not satisfy the rule.
+	writeContent(t, cfg, "secret.md", "---\ntitle: Secret\ndate: 2024-05-04\ndraft: true\n---\nbody\n")
+	if err := os.WriteFile(cfg.redirectsFile, []byte("- from: /legacy/\n  to: /secret/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := run(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "target /secret/ was not generated") {
+		t.Fatalf("run error = %v, want target /secret/ was not generated", err)
+	}
+}
+
+func readOutput(t *testing.T, cfg config, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(cfg.outputDir, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRedirectToWithAliases(t *testing.T) {
+	const src = "---\ntitle: Moved\ndate: 2024-05-03\nredirect_to: https://new.example/moved/\naliases: [/old-moved/]\n---\nbody\n"
+	t.Run("pages", func(t *testing.T) {
+		cfg := testConfig(t)
+		writeContent(t, cfg, "moved.md", src)
+		buildSite(t, cfg)
+		for _, rel := range []string{"moved/index.html", "old-moved/index.html"} {
+			if page := readOutput(t, cfg, rel); !strings.Contains(page, `url=https://new.example/moved/`) {
+				t.Errorf("%s does not redirect to the external URL:\n%s", rel, page)
+			}
+		}
+	})
+	t.Run("netlify", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.redirectsFormat = hostNetlify
+		writeContent(t, cfg, "moved.md", src)
+		buildSite(t, cfg)
+		got := readOutput(t, cfg, "_redirects")
+		want := "/moved/ https://new.example/moved/ 301\n/old-moved/ https://new.example/moved/ 301\n"
+		if got != want {
+			t.Errorf("_redirects = %q, want %q", got, want)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.outputDir, "moved", "index.html")); err == nil {
+			t.Error("moved/index.html written although _redirects carries the rule")
+		}
+	})
+}
+
+func TestRedirectToOnDraft(t *testing.T) {
+	const src = "---\ntitle: Draft Move\ndate: 2024-05-03\ndraft: true\nredirect_to: https://new.example/draft/\naliases: [/old-draft/]\n---\nbody\n"
+	t.Run("with drafts", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.drafts = true
+		cfg.redirectsFormat = hostNetlify
+		writeContent(t, cfg, "draft-move.md", src)
+		buildSite(t, cfg)
+		page := readOutput(t, cfg, "draft-move/index.html")
+		if !strings.Contains(page, "Draft Move") || strings.Contains(page, "new.example") {
+			t.Errorf("draft page is not a normal page:\n%s", page)
+		}
+		if got := readOutput(t, cfg, "_redirects"); strings.TrimSpace(got) != "" {
+			t.Errorf("_redirects = %q, want no rules for a draft", got)
+		}
+	})
+	t.Run("without drafts", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.redirectsFormat = hostNetlify
+		writeContent(t, cfg, "keep.md", "---\ntitle: Keep\ndate: 2024-05-01\n---\nbody\n")
+		writeContent(t, cfg, "draft-move.md", src)
+		buildSite(t, cfg)
+		if _, err := os.Stat(filepath.Join(cfg.outputDir, "draft-move")); err == nil {
+			t.Error("draft-move written without -drafts")
+		}
+		if got := readOutput(t, cfg, "_redirects"); strings.TrimSpace(got) != "" {
+			t.Errorf("_redirects = %q, want no rules for a draft", got)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const searchIndexURL = "/search/index.json"
+
+// defaultSearchTemplate is used when templates/search.html does not exist.
+// It only provides the markup; a script such as /assets/search.js is
+// expected to fetch data-index and fill in the results list.
+const defaultSearchTemplate = `{{ define "content" }}
+<section class="search">
+  <h2>{{ label "search" }}</h2>
+  <form class="search-form" role="search" action="{{ relURL "/search/" }}">
+    <input type="search" name="q" placeholder="{{ label "searchPlaceholder" }}" aria-label="{{ label "searchTerms" }}" autocomplete="off">
+  </form>
+  <ul class="search-results" data-index="{{ relURL .SearchIndexURL }}"></ul>
+  <script src="{{ relURL "/assets/search.js" }}" defer></script>
+</section>
+{{ end }}`
+
+type searchEntry struct {
+	Title     string   `json:"title"`
+	Permalink string   `json:"permalink"`
+	Date      string   `json:"date,omitempty"`
+	Tags      []string `json:"tags"`
+	Body      string   `json:"body"`
+}
+
+// renderSearchIndex writes search/index.json with one entry per published
+// post, in the same newest-first order as the index page.
+func renderSearchIndex(cfg config, posts []post) error {
+	entries := make([]searchEntry, 0, len(posts))
+	for _, p := range posts {
+		entry := searchEntry{
+			Title:     p.Title,
+			Permalink: cfg.baseURL + "/" + p.Slug + "/",
+			Tags:      make([]string, 0, len(p.Tags)),
+			Body:      truncateRunes(p.PlainText, cfg.searchIndexLimit),
+		}
+		if !p.Date.IsZero() {
+			entry.Date = p.Date.Format(time.RFC3339)
+		}
+		for _, t := range p.Tags {
+			if t = strings.TrimSpace(t); t != "" {
+				entry.Tags = append(entry.Tags, t)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("encode search index: %w", err)
+	}
+
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(searchIndexURL, "/")))
+	if err := ensureDir(filepath.Dir(target)); err != nil {
+		return err
+	}
+	if err := cfg.out.writeText(target, data); err != nil {
+		return fmt.Errorf("write search index: %w", err)
+	}
+	return nil
+}
+
+// truncateRunes cuts s to at most limit runes; limit <= 0 keeps everything.
+func truncateRunes(s string, limit int) string {
+	if limit <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
+
+// renderSearch writes the /search/ page that hosts the client-side search UI.
+func renderSearch(cfg config, tpl *template.Template) error {
+	dir := filepath.Join(cfg.outputDir, "search")
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, "index.html")
+	data := map[string]any{
+		"Title":          uiLabel(cfg.language, "search"),
+		"SearchIndexURL": searchIndexURL,
+		"Site":           newSite(cfg),
+	}
+	if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+		return &RenderError{Kind: "search page", Target: target, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// seriesGroup is a multi-part series: posts sharing a `series:` name in
+// front matter, in reading order.
+type seriesGroup struct {
+	Name    string
+	Slug    string
+	Posts   []post
+	Updated time.Time
+}
+
+// buildSeries groups posts by series and orders each group by seriesPart,
+// then date. Series with a single part are left out; the result is sorted by
+// most recently updated first.
+func buildSeries(posts []post) []seriesGroup {
+	groupMap := make(map[string]*seriesGroup)
+	for _, p := range posts {
+		name := strings.TrimSpace(p.Series)
+		if name == "" {
+			continue
+		}
+		slug := tagSlug(name)
+		group, ok := groupMap[slug]
+		if !ok {
+			group = &seriesGroup{Name: name, Slug: slug}
+			groupMap[slug] = group
+		}
+		group.Posts = append(group.Posts, p)
+		if updated := firstNonZero(p.Git.LastCommitDate, p.Date); updated.After(group.Updated) {
+			group.Updated = updated
+		}
+	}
+
+	result := make([]seriesGroup, 0, len(groupMap))
+	for _, g := range groupMap {
+		if len(g.Posts) < 2 {
+			continue
+		}
+		sort.SliceStable(g.Posts, func(i, j int) bool {
+			a, b := g.Posts[i], g.Posts[j]
+			if a.SeriesPart != b.SeriesPart {
+				if a.SeriesPart == 0 || b.SeriesPart == 0 {
+					return b.SeriesPart == 0
+				}
+				return a.SeriesPart < b.SeriesPart
+			}
+			return a.Date.Before(b.Date)
+		})
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Updated.Equal(result[j].Updated) {
+			return result[i].Updated.After(result[j].Updated)
+		}
+		return result[i].Slug < result[j].Slug
+	})
+	return result
+}
+
+// renderSeriesIndex writes /series/ listing every multi-part series.
+func renderSeriesIndex(cfg config, tpl *template.Template, series []seriesGroup) error {
+	dir := filepath.Join(cfg.outputDir, "series")
+	if err := ensureDir(dir); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, "index.html")
+	data := map[string]any{
+		"Title":  uiLabel(cfg.language, "seriesIndex"),
+		"Series": series,
+		"Site":   newSite(cfg),
+	}
+	if err := cfg.out.writeHTML(target, tpl, "base", data); err != nil {
+		return &RenderError{Kind: "series index", Target: target, Err: err}
+	}
+	return nil
+}
+
+func firstNonZero(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+const serviceWorkerURL = "/sw.js"
+
+// serviceWorkerRegistration is exposed as .Site.ServiceWorkerScript so the
+// layout decides where, or whether, to register the worker.
+const serviceWorkerRegistration = `<script>if ("serviceWorker" in navigator) { navigator.serviceWorker.register(%s); }</script>`
+
+// serviceWorkerSource caches assets first and fetches pages from the network
+// first, falling back to the cached copy of pages read before. Old caches
+// are dropped when the version changes.
+var serviceWorkerSource = texttemplate.Must(texttemplate.New("sw").Parse(`const VERSION = {{ .Version }};
+const PRECACHE = {{ .Precache }};
+const HOME = {{ .Home }};
+const CACHE = "site-" + VERSION;
+
+self.addEventListener("install", (event) => {
+  event.waitUntil(caches.open(CACHE).then((cache) => cache.addAll(PRECACHE)).then(() => self.skipWaiting()));
+});
+
+self.addEventListener("activate", (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((key) => key !== CACHE).map((key) => caches.delete(key))))
+      .then(() => self.clients.claim())
+  );
+});
+
+self.addEventListener("fetch", (event) => {
+  const request = event.request;
+  const url = new URL(request.url);
+  if (request.method !== "GET" || url.origin !== self.location.origin) {
+    return;
+  }
+  if (url.pathname.startsWith(HOME + "assets/")) {
+    event.respondWith(
+      caches.match(request).then((cached) => cached || fetch(request).then((response) => {
+        const copy = response.clone();
+        caches.open(CACHE).then((cache) => cache.put(request, copy));
+        return response;
+      }))
+    );
+    return;
+  }
+  if (request.mode === "navigate" || (request.headers.get("accept") || "").includes("text/html")) {
+    event.respondWith(
+      fetch(request).then((response) => {
+        const copy = response.clone();
+        caches.open(CACHE).then((cache) => cache.put(request, copy));
+        return response;
+      }).catch(() => caches.match(request).then((cached) => cached || caches.match(HOME)))
+    );
+  }
+});
+`))
+
+// serviceWorkerPrecache lists the homepage plus every stylesheet and font in
+// the output's asset directory, sorted.
+func serviceWorkerPrecache(outDir string) ([]string, error) {
+	urls := []string{"/"}
+	assetDir := filepath.Join(outDir, "assets")
+	if _, err := os.Stat(assetDir); err != nil {
+		return urls, nil
+	}
+	err := filepath.WalkDir(assetDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".css", ".woff", ".woff2", ".ttf", ".otf":
+		default:
+			return nil
+		}
+		rel, err := filepath.Rel(outDir, p)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, "/"+filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(urls[1:])
+	return urls, err
+}
+
+// renderServiceWorker writes sw.js. Its cache version is a hash of the
+// precached files, so a build that changes any of them invalidates old
+// caches. It must run after assets have been copied.
+func renderServiceWorker(cfg config) error {
+	precache, err := serviceWorkerPrecache(cfg.outputDir)
+	if err != nil {
+		return fmt.Errorf("scan precache: %w", err)
+	}
+	h := sha256.New()
+	for _, u := range precache {
+		file := filepath.Join(cfg.outputDir, filepath.FromSlash(strings.TrimPrefix(u, "/")))
+		if u == "/" {
+			file = filepath.Join(cfg.outputDir, "index.html")
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("hash precache %s: %w", u, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", u, len(data))
+		h.Write(data)
+	}
+
+	version, _ := json.Marshal(hex.EncodeToString(h.Sum(nil))[:12])
+	for i, u := range precache {
+		precache[i] = relURL(cfg, u)
+	}
+	list, _ := json.Marshal(precache)
+	home, _ := json.Marshal(relURL(cfg, "/"))
+	var buf bytes.Buffer
+	if err := serviceWorkerSource.Execute(&buf, map[string]string{
+		"Version":  string(version),
+		"Precache": string(list),
+		"Home":     string(home),
+	}); err != nil {
+		return fmt.Errorf("render service worker: %w", err)
+	}
+	target := filepath.Join(cfg.outputDir, strings.TrimPrefix(serviceWorkerURL, "/"))
+	if err := cfg.out.writeText(target, buf.Bytes()); err != nil {
+		return fmt.Errorf("write service worker: %w", err)
+	}
+	return nil
+}
+
+// serviceWorkerScript returns the registration snippet for .Site, or ""
+// when the worker is disabled.
+func serviceWorkerScript(cfg config) template.HTML {
+	if !cfg.serviceWorker {
+		return ""
+	}
+	src, _ := json.Marshal(relURL(cfg, serviceWorkerURL))
+	return template.HTML(fmt.Sprintf(serviceWorkerRegistration, src))
+}
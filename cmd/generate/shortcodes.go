@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	stdhtml "html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// csvShortcodePattern matches {{< csv "path" >}} with optional key=value
+// options, e.g. {{< csv "data/results.csv" header=false >}}.
+var csvShortcodePattern = regexp.MustCompile(`\{\{<\s*csv\s+"([^"]+)"((?:\s+\w+=[^\s>]+)*)\s*>\}\}`)
+
+// expandShortcodes expands snippets, then replaces csv shortcodes in body
+// with HTML tables before the markdown is rendered. Paths are relative to
+// the content directory root the post was found in. Shortcodes inside code
+// spans and code blocks are left as written.
+func expandShortcodes(cfg config, root string, body []byte) ([]byte, error) {
+	body, err := expandSnippets(cfg, body)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	out := replaceOutsideCode(csvShortcodePattern, body, func(m []byte) []byte {
+		if firstErr != nil {
+			return m
+		}
+		sub := csvShortcodePattern.FindSubmatch(m)
+		header := cfg.csvHeader
+		for _, opt := range strings.Fields(string(sub[2])) {
+			key, val, _ := strings.Cut(opt, "=")
+			switch key {
+			case "header":
+				b, err := strconv.ParseBool(val)
+				if err != nil {
+					firstErr = fmt.Errorf("csv %s: invalid header=%q", sub[1], val)
+					return m
+				}
+				header = b
+			default:
+				firstErr = fmt.Errorf("csv %s: unknown option %q", sub[1], key)
+				return m
+			}
+		}
+		table, err := csvTable(root, string(sub[1]), header)
+		if err != nil {
+			firstErr = err
+			return m
+		}
+		return table
+	})
+	return out, firstErr
+}
+
+// replaceOutsideCode is re.ReplaceAllFunc for markdown: matches that start
+// inside a code span or code block are kept, so a post can show shortcode
+// syntax.
+func replaceOutsideCode(re *regexp.Regexp, src []byte, repl func([]byte) []byte) []byte {
+	matches := re.FindAllIndex(src, -1)
+	if len(matches) == 0 {
+		return src
+	}
+	code := codeRanges(src)
+	var out bytes.Buffer
+	last := 0
+	for _, m := range matches {
+		if inRanges(code, m[0]) {
+			continue
+		}
+		out.Write(src[last:m[0]])
+		out.Write(repl(src[m[0]:m[1]]))
+		last = m[1]
+	}
+	out.Write(src[last:])
+	return out.Bytes()
+}
+
+// codeRanges returns the byte ranges of src that markdown renders as code.
+func codeRanges(src []byte) [][2]int {
+	var ranges [][2]int
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				ranges = append(ranges, [2]int{seg.Start, seg.Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if t, ok := c.(*ast.Text); ok {
+					ranges = append(ranges, [2]int{t.Segment.Start, t.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return ranges
+}
+
+func inRanges(ranges [][2]int, i int) bool {
+	for _, r := range ranges {
+		if r[0] <= i && i < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// csvTable reads name under root and renders it as an HTML table.
+func csvTable(root, name string, header bool) ([]byte, error) {
+	file := filepath.Join(root, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(root, file); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("csv %s: path escapes content directory %s", name, root)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("csv %s: %w", name, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\n<table class=\"csv-table\">\n")
+	if header && len(rows) > 0 {
+		writeTableRow(&buf, "th", rows[0])
+		rows = rows[1:]
+	}
+	if len(rows) > 0 {
+		buf.WriteString("<tbody>\n")
+		for _, row := range rows {
+			writeTableRow(&buf, "td", row)
+		}
+		buf.WriteString("</tbody>\n")
+	}
+	buf.WriteString("</table>\n")
+	return buf.Bytes(), nil
+}
+
+func writeTableRow(buf *bytes.Buffer, cell string, fields []string) {
+	if cell == "th" {
+		buf.WriteString("<thead>\n")
+	}
+	buf.WriteString("<tr>")
+	for _, f := range fields {
+		fmt.Fprintf(buf, "<%s>%s</%s>", cell, stdhtml.EscapeString(f), cell)
+	}
+	buf.WriteString("</tr>\n")
+	if cell == "th" {
+		buf.WriteString("</thead>\n")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandCSVShortcode(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "scores.csv"), []byte("name,score\n<kim>,3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "header row",
+			body: `{{< csv "scores.csv" >}}`,
+			want: "\n<table class=\"csv-table\">\n<thead>\n<tr><th>name</th><th>score</th></tr>\n</thead>\n<tbody>\n<tr><td>&lt;kim&gt;</td><td>3</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "header=false",
+			body: `{{< csv "scores.csv" header=false >}}`,
+			want: "\n<table class=\"csv-table\">\n<tbody>\n<tr><td>name</td><td>score</td></tr>\n<tr><td>&lt;kim&gt;</td><td>3</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "fenced code block",
+			body: "```\n{{< csv \"scores.csv\" >}}\n```\n",
+			want: "```\n{{< csv \"scores.csv\" >}}\n```\n",
+		},
+		{
+			name: "indented code block",
+			body: "text\n\n    {{< csv \"scores.csv\" >}}\n",
+			want: "text\n\n    {{< csv \"scores.csv\" >}}\n",
+		},
+		{
+			name: "code span",
+			body: "Write `{{< csv \"scores.csv\" >}}` to include a table.\n",
+			want: "Write `{{< csv \"scores.csv\" >}}` to include a table.\n",
+		},
+	}
+	cfg := testConfig(t)
+	cfg.csvHeader = true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandShortcodes(cfg, root, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("expandShortcodes: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCSVShortcodeErrors(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "scores.csv"), []byte("a,b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		`{{< csv "../secret.csv" >}}`:             "escapes content directory",
+		`{{< csv "missing.csv" >}}`:               "missing.csv",
+		`{{< csv "scores.csv" header=maybe >}}`:   `invalid header="maybe"`,
+		`{{< csv "scores.csv" caption=scores >}}`: `unknown option "caption"`,
+	}
+	cfg := testConfig(t)
+	for body, want := range tests {
+		_, err := expandShortcodes(cfg, root, []byte(body))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", body, err, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSlugUsesForwardSlashes(t *testing.T) {
+	root := filepath.Join("site", "content")
+	tests := []struct {
+		file string
+		want string
+	}{
+		{filepath.Join(root, "hello.md"), "hello"},
+		{filepath.Join(root, "guides", "go", "Intro.md"), "guides/go/intro"},
+		{filepath.Join(root, "guides", ".", "go", "..", "setup.markdown"), "guides/setup"},
+		{root + string(filepath.Separator) + "notes" + string(filepath.Separator) + "a.b.md", "notes/a.b"},
+	}
+	for _, tt := range tests {
+		got := buildSlug(root, tt.file)
+		if got != tt.want {
+			t.Errorf("buildSlug(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+		if url := "/" + got + "/"; strings.Contains(url, `\`) {
+			t.Errorf("URL %q has a backslash", url)
+		}
+	}
+}
+
+func TestLoadPostsNestedSlugs(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.dateInPath = dateInPathMonth
+	writeContent(t, cfg, filepath.Join("guides", "go", "intro.md"), "---\ntitle: Intro\ndate: 2024-05-03\n---\nbody\n")
+	posts, err := loadPosts(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	if want := "2024/05/guides/go/intro"; posts[0].Slug != want {
+		t.Errorf("slug = %q, want %q", posts[0].Slug, want)
+	}
+}
+
+func TestTagSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		slug string
+		key  string
+	}{
+		{"Go", "go", "go"},
+		{"  Go Lang ", "go-lang", "go-lang"},
+		{"k8s_ops", "k8s-ops", "k8s-ops"},
+		{"C++", "c", "c"},
+		{"café", "café", "café"},
+		{"한국어", "hangukeo", "한국어"},
+		{"Go 언어", "go-eoneo", "go-언어"},
+		{"갑", "gap", "갑"},
+		{"값", "gap", "값"},
+		{"!!!", "tag", "tag"},
+		{"", "tag", "tag"},
+	}
+	for _, tt := range tests {
+		if got := tagSlug(tt.name); got != tt.slug {
+			t.Errorf("tagSlug(%q) = %q, want %q", tt.name, got, tt.slug)
+		}
+		if got := tagKey(tt.name); got != tt.key {
+			t.Errorf("tagKey(%q) = %q, want %q", tt.name, got, tt.key)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+)
+
+// snippetShortcodePattern matches {{< snippet "name" >}} with an optional
+// "." and key="value" parameters, e.g.
+// {{< snippet "warning" . title="주의" >}}.
+var snippetShortcodePattern = regexp.MustCompile(`\{\{<\s*snippet\s+"([^"]+)"((?:\s+(?:\.|\w+="[^"]*"))*)\s*>\}\}`)
+
+var snippetParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// loadSnippets parses every .md file in dir as a snippet named after its
+// stem. Snippet text is a text/template over the shortcode's parameters, so
+// {{ .title }} inserts title="..."; a parameter the snippet uses but the
+// shortcode does not pass is an error. A missing directory yields nil.
+func loadSnippets(dir string) (map[string]*texttemplate.Template, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snippets %s: %w", dir, err)
+	}
+	snippets := make(map[string]*texttemplate.Template)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read snippet %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(e.Name(), ".md")
+		tpl, err := texttemplate.New(name).Option("missingkey=error").Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("parse snippet %s: %w", path, err)
+		}
+		snippets[name] = tpl
+	}
+	return snippets, nil
+}
+
+// expandSnippets replaces snippet shortcodes in body with the rendered
+// snippet text, before any other shortcode or markdown processing.
+// Shortcodes inside code spans and code blocks are left as written.
+func expandSnippets(cfg config, body []byte) ([]byte, error) {
+	var firstErr error
+	out := replaceOutsideCode(snippetShortcodePattern, body, func(m []byte) []byte {
+		if firstErr != nil {
+			return m
+		}
+		sub := snippetShortcodePattern.FindSubmatch(m)
+		name := string(sub[1])
+		tpl := cfg.snippets[name]
+		if tpl == nil {
+			firstErr = fmt.Errorf("snippet %q: no %s.md in %s", name, name, cfg.snippetDir)
+			return m
+		}
+		params := make(map[string]string)
+		for _, p := range snippetParamPattern.FindAllSubmatch(sub[2], -1) {
+			params[string(p[1])] = string(p[2])
+		}
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, params); err != nil {
+			firstErr = fmt.Errorf("snippet %q: %w", name, err)
+			return m
+		}
+		return bytes.TrimRight(buf.Bytes(), "\n")
+	})
+	return out, firstErr
+}
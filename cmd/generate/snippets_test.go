@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSnippets(t *testing.T, cfg *config, files map[string]string) {
+	t.Helper()
+	cfg.snippetDir = filepath.Join(t.TempDir(), "snippets")
+	if err := os.MkdirAll(cfg.snippetDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(cfg.snippetDir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snippets, err := loadSnippets(cfg.snippetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.snippets = snippets
+}
+
+func TestExpandSnippets(t *testing.T) {
+	cfg := testConfig(t)
+	testSnippets(t, &cfg, map[string]string{
+		"warning.md": "> **{{ .title }}**: 되돌릴 수 없습니다.\n",
+		"plain.md":   "plain text\n",
+		"notes.txt":  "not a snippet\n",
+	})
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "parameters",
+			body: "before\n\n{{< snippet \"warning\" . title=\"주의\" >}}\n\nafter\n",
+			want: "before\n\n> **주의**: 되돌릴 수 없습니다.\n\nafter\n",
+		},
+		{
+			name: "no parameters",
+			body: "{{< snippet \"plain\" >}}",
+			want: "plain text",
+		},
+		{
+			name: "fenced code block",
+			body: "~~~markdown\n{{< snippet \"plain\" >}}\n~~~\n",
+			want: "~~~markdown\n{{< snippet \"plain\" >}}\n~~~\n",
+		},
+		{
+			name: "code span",
+			body: "Use `{{< snippet \"plain\" >}}` and {{< snippet \"plain\" >}}.\n",
+			want: "Use `{{< snippet \"plain\" >}}` and plain text.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandSnippets(cfg, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("expandSnippets: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSnippetsErrors(t *testing.T) {
+	cfg := testConfig(t)
+	testSnippets(t, &cfg, map[string]string{"warning.md": "{{ .title }}\n"})
+	tests := map[string]string{
+		`{{< snippet "missing" >}}`: `snippet "missing": no missing.md`,
+		`{{< snippet "warning" >}}`: `snippet "warning"`,
+	}
+	for body, want := range tests {
+		_, err := expandSnippets(cfg, []byte(body))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", body, err, want)
+		}
+	}
+}
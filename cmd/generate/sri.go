@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// assetIntegrity holds the subresource integrity value for each local
+// stylesheet and script, keyed by URL path (e.g. "/assets/style.css").
+type assetIntegrity struct {
+	hashes map[string]string
+
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// sriHashed reports whether an asset gets an integrity value. Other files
+// (images, fonts) are never loaded with integrity checks.
+func sriHashed(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".css", ".js":
+		return true
+	}
+	return false
+}
+
+// buildAssetIntegrity hashes the stylesheets and scripts under assetDir with
+// sha384. The source files are hashed, so it can run before pages are
+// rendered; they are copied verbatim.
+func buildAssetIntegrity(assetDir string) (*assetIntegrity, error) {
+	a := &assetIntegrity{hashes: make(map[string]string), warned: make(map[string]bool)}
+	if _, err := os.Stat(assetDir); errors.Is(err, fs.ErrNotExist) {
+		return a, nil
+	}
+	err := filepath.WalkDir(assetDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !sriHashed(p) {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read asset %s: %w", p, err)
+		}
+		rel, err := filepath.Rel(assetDir, p)
+		if err != nil {
+			return err
+		}
+		sum := sha512.Sum384(data)
+		a.hashes["/assets/"+filepath.ToSlash(rel)] = "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hash assets: %w", err)
+	}
+	return a, nil
+}
+
+// integrityFunc returns the "integrity" template function. It yields "" for
+// files that are not hashed and when -sri is off. A referenced stylesheet or
+// script missing from the asset directory fails the build under -strict and
+// is warned about once otherwise.
+func integrityFunc(cfg config) func(string) (string, error) {
+	return func(url string) (string, error) {
+		if cfg.assetIntegrity == nil || !sriHashed(url) {
+			return "", nil
+		}
+		a := cfg.assetIntegrity
+		if h, ok := a.hashes[url]; ok {
+			return h, nil
+		}
+		if cfg.strict {
+			return "", fmt.Errorf("integrity: %s is not in the asset directory %s", url, cfg.assetDir)
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if !a.warned[url] {
+			a.warned[url] = true
+			cfg.logger.Warn(fmt.Sprintf("템플릿이 참조한 %s 파일이 에셋 디렉터리에 없어 integrity를 생략합니다.", url))
+		}
+		return "", nil
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagAliases maps the tagKey of an alias to the canonical tag name it folds
+// into, e.g. "k8s" -> "Kubernetes".
+type tagAliases map[string]string
+
+func (a tagAliases) String() string {
+	var parts []string
+	for alias, canonical := range a {
+		parts = append(parts, alias+"="+canonical)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Set parses a single "alias=canonical" pair so the flag can be repeated.
+func (a tagAliases) Set(v string) error {
+	alias, canonical, ok := strings.Cut(v, "=")
+	alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+	if !ok || alias == "" || canonical == "" {
+		return fmt.Errorf("want alias=canonical, got %q", v)
+	}
+	if tagKey(alias) == tagKey(canonical) {
+		return fmt.Errorf("alias %q names the same tag as %q", alias, canonical)
+	}
+	a[tagKey(alias)] = canonical
+	return nil
+}
+
+// resolve returns the canonical name for tag, or tag itself when it is not
+// an alias.
+func (a tagAliases) resolve(tag string) string {
+	if canonical, ok := a[tagKey(tag)]; ok {
+		return canonical
+	}
+	return tag
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagAliasesSet(t *testing.T) {
+	aliases := tagAliases{}
+	if err := aliases.Set(" K8s = Kubernetes "); err != nil {
+		t.Fatal(err)
+	}
+	if got := aliases.resolve("k8s"); got != "Kubernetes" {
+		t.Errorf("resolve(k8s) = %q, want Kubernetes", got)
+	}
+	if got := aliases.resolve("go"); got != "go" {
+		t.Errorf("resolve(go) = %q, want go unchanged", got)
+	}
+	for v, want := range map[string]string{
+		"k8s":             "want alias=canonical",
+		"=Kubernetes":     "want alias=canonical",
+		"Go Lang=go-lang": `alias "Go Lang" names the same tag as "go-lang"`,
+	} {
+		if err := aliases.Set(v); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Set(%q) = %v, want %q", v, err, want)
+		}
+	}
+}
+
+func TestTagAliasesFoldTagPages(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.tagAliases = tagAliases{}
+	if err := cfg.tagAliases.Set("k8s=Kubernetes"); err != nil {
+		t.Fatal(err)
+	}
+	writeContent(t, cfg, "a.md", "---\ntitle: A\ndate: 2024-05-03\ntags: [k8s]\n---\nbody\n")
+	writeContent(t, cfg, "b.md", "---\ntitle: B\ndate: 2024-05-04\ntags: [Kubernetes, K8S]\n---\nbody\n")
+	writeContent(t, cfg, "c.md", "---\ntitle: C\ndate: 2024-05-05\ntags: [kubernetes]\n---\nbody\n")
+	buildSite(t, cfg)
+
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "tags", "k8s")); err == nil {
+		t.Error("the alias k8s got its own tag page")
+	}
+	page := readOutput(t, cfg, "tags/kubernetes/index.html")
+	for _, slug := range []string{"/a/", "/b/", "/c/"} {
+		if strings.Count(page, `href="`+slug+`"`) != 1 {
+			t.Errorf("tags/kubernetes/ should list %s once:\n%s", slug, page)
+		}
+	}
+	if post := readOutput(t, cfg, "a/index.html"); !strings.Contains(post, `<a class="tag" href="/tags/kubernetes/">Kubernetes</a>`) {
+		t.Errorf("a does not link its k8s tag as Kubernetes:\n%s", post)
+	}
+	if index := readOutput(t, cfg, "tags/index.html"); strings.Count(index, "/tags/kubernetes/") != 1 {
+		t.Errorf("tag index should list kubernetes once:\n%s", index)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type tagGraph struct {
+	Nodes []tagGraphNode `json:"nodes"`
+	Edges []tagGraphEdge `json:"edges"`
+}
+
+type tagGraphNode struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// tagGraphEdge links two tags that appear together; Weight is the number of
+// posts carrying both. Source is always the lexically smaller slug.
+type tagGraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+// buildTagGraph derives tag co-occurrence from posts. Tags are keyed by slug
+// so the graph lines up with the generated tag pages.
+func buildTagGraph(posts []post, groups []tagGroup, aliases tagAliases) tagGraph {
+	graph := tagGraph{
+		Nodes: make([]tagGraphNode, 0, len(groups)),
+		Edges: []tagGraphEdge{},
+	}
+	slugByKey := make(tagSlugs, len(groups))
+	slugByKey.add(groups)
+	for _, g := range groups {
+		graph.Nodes = append(graph.Nodes, tagGraphNode{
+			ID:    g.Slug,
+			Name:  g.Name,
+			URL:   tagPath(g.Slug),
+			Count: len(g.Posts),
+		})
+	}
+
+	weights := make(map[[2]string]int)
+	for _, p := range posts {
+		seen := make(map[string]struct{}, len(p.Tags))
+		var slugs []string
+		for _, raw := range p.Tags {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			slug, ok := slugByKey[tagKey(aliases.resolve(raw))]
+			if !ok {
+				continue
+			}
+			if _, ok := seen[slug]; ok {
+				continue
+			}
+			seen[slug] = struct{}{}
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+		for i := range slugs {
+			for j := i + 1; j < len(slugs); j++ {
+				weights[[2]string{slugs[i], slugs[j]}]++
+			}
+		}
+	}
+
+	for pair, w := range weights {
+		graph.Edges = append(graph.Edges, tagGraphEdge{Source: pair[0], Target: pair[1], Weight: w})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	return graph
+}
+
+func renderTagGraph(cfg config, posts []post, groups []tagGroup) error {
+	data, err := json.Marshal(buildTagGraph(posts, groups, cfg.tagAliases))
+	if err != nil {
+		return fmt.Errorf("encode tag graph: %w", err)
+	}
+	target := filepath.Join(cfg.outputDir, "tag-graph.json")
+	if err := cfg.out.writeText(target, data); err != nil {
+		return fmt.Errorf("write tag graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDraftOnlyTagHasNoPage(t *testing.T) {
+	cfg := testConfig(t)
+	writeContent(t, cfg, "public.md", "---\ntitle: Public\ndate: 2024-05-03\ntags: [go]\n---\nbody\n")
+	writeContent(t, cfg, "hidden.md", "---\ntitle: Hidden\ndate: 2024-05-04\ndraft: true\ntags: [go, secret]\n---\nbody\n")
+	buildSite(t, cfg)
+
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "tags", "go", "index.html")); err != nil {
+		t.Errorf("tag page for go: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "tags", "secret")); err == nil {
+		t.Error("draft-only tag secret got a tag page")
+	}
+	if index := readOutput(t, cfg, "tags/index.html"); strings.Contains(index, "secret") {
+		t.Error("tag index lists the draft-only tag secret")
+	}
+}
+
+func TestNonEmptyTagGroups(t *testing.T) {
+	tags := []tagGroup{
+		{Name: "go", Slug: "go", Posts: []post{{Slug: "public"}}},
+		{Name: "secret", Slug: "secret"},
+	}
+	got := nonEmptyTagGroups(tags)
+	if len(got) != 1 || got[0].Name != "go" {
+		t.Errorf("nonEmptyTagGroups = %+v, want only go", got)
+	}
+}
+
+func TestAssignTagSlugsDisambiguates(t *testing.T) {
+	cfg := testConfig(t)
+	for _, order := range [][]string{{"갑", "값", "gap-2"}, {"gap-2", "값", "갑"}} {
+		var posts []post
+		for i, tag := range order {
+			posts = append(posts, post{Slug: fmt.Sprint("p", i), Tags: []string{tag}})
+		}
+		got := make(map[string]string)
+		for _, g := range buildTagGroups(cfg.logger, posts, nil) {
+			got[g.Name] = g.Slug
+		}
+		want := map[string]string{"갑": "gap", "gap-2": "gap-2", "값": "gap-3"}
+		if !maps.Equal(got, want) {
+			t.Errorf("tags %v: slugs = %v, want %v", order, got, want)
+		}
+	}
+}
+
+func TestCollidingHangulTags(t *testing.T) {
+	cfg := testConfig(t)
+	writeContent(t, cfg, "a.md", "---\ntitle: A\ndate: 2024-05-03\ntags: [갑]\n---\nbody\n")
+	writeContent(t, cfg, "b.md", "---\ntitle: B\ndate: 2024-05-04\ntags: [값]\n---\nbody\n")
+	buildSite(t, cfg)
+
+	if page := readOutput(t, cfg, "tags/gap/index.html"); !strings.Contains(page, "/a/") || strings.Contains(page, "/b/") {
+		t.Errorf("tags/gap/ should list only a:\n%s", page)
+	}
+	if page := readOutput(t, cfg, "tags/gap-2/index.html"); !strings.Contains(page, "/b/") || strings.Contains(page, "/a/") {
+		t.Errorf("tags/gap-2/ should list only b:\n%s", page)
+	}
+	if page := readOutput(t, cfg, "b/index.html"); !strings.Contains(page, `href="/tags/gap-2/"`) {
+		t.Errorf("b does not link its tag page /tags/gap-2/:\n%s", page)
+	}
+	if page := readOutput(t, cfg, "tags/값/index.html"); !strings.Contains(page, defaultBaseURL+"/tags/gap-2/") {
+		t.Errorf("old URL of 값 does not redirect to /tags/gap-2/:\n%s", page)
+	}
+
+	cfg.redirectsFormat = hostNetlify
+	buildSite(t, cfg)
+	want := "/tags/갑/ /tags/gap/ 301\n/tags/값/ /tags/gap-2/ 301\n"
+	if got := readOutput(t, cfg, "_redirects"); got != want {
+		t.Errorf("_redirects = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// taskListExtension adds styling hooks to GFM task lists: class="task-list"
+// on any list holding a task item and class="task-list-item" on each task
+// item. Nested lists are classed independently.
+type taskListExtension struct{}
+
+func (taskListExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(taskListClasser{}, 200),
+	))
+}
+
+type taskListClasser struct{}
+
+func (taskListClasser) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		item, ok := n.(*ast.ListItem)
+		if !entering || !ok || !isTaskItem(item) {
+			return ast.WalkContinue, nil
+		}
+		item.SetAttributeString("class", []byte("task-list-item"))
+		if list := item.Parent(); list != nil {
+			list.SetAttributeString("class", []byte("task-list"))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// isTaskItem reports whether item starts with a GFM task checkbox.
+func isTaskItem(item *ast.ListItem) bool {
+	block := item.FirstChild()
+	if block == nil {
+		return false
+	}
+	_, ok := block.FirstChild().(*east.TaskCheckBox)
+	return ok
+}
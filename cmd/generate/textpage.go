@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// textPageWidth is the column width index.txt is wrapped to. Wide (Hangul,
+// Han, kana) characters count as two columns, as in a terminal.
+const textPageWidth = 80
+
+// renderTextPage formats p for terminal readers: a title and date header,
+// then each block wrapped to textPageWidth. Code blocks are kept verbatim and
+// indented by four spaces; list items are bulleted.
+func renderTextPage(cfg config, p post) string {
+	var b strings.Builder
+	b.WriteString(p.Title + "\n")
+	if !p.Date.IsZero() {
+		b.WriteString(formatDate(p.Date, cfg.location) + "\n")
+	}
+	var blocks []string
+	collectTextPageBlocks(p.doc, p.source, "", &blocks)
+	for _, block := range blocks {
+		b.WriteString("\n" + block + "\n")
+	}
+	b.WriteString("\n" + cfg.baseURL + "/" + p.Slug + "/\n")
+	return b.String()
+}
+
+func collectTextPageBlocks(n ast.Node, src []byte, indent string, blocks *[]string) {
+	if n == nil {
+		return
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Heading, *ast.Paragraph, *ast.TextBlock:
+			if text := inlineText(c, src, plainTextOptions{}); text != "" {
+				*blocks = append(*blocks, wrapText(text, indent, indent, textPageWidth))
+			}
+		case *ast.ListItem:
+			var inner []string
+			collectTextPageBlocks(c, src, indent+"  ", &inner)
+			if len(inner) > 0 {
+				inner[0] = indent + "- " + strings.TrimPrefix(inner[0], indent+"  ")
+			}
+			*blocks = append(*blocks, strings.Join(inner, "\n"))
+		case *east.TableHeader, *east.TableRow:
+			var cells []string
+			for cell := c.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, inlineText(cell, src, plainTextOptions{}))
+			}
+			*blocks = append(*blocks, wrapText(strings.Join(cells, " | "), indent, indent, textPageWidth))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			code := strings.TrimRight(string(blockLines(c, src)), "\n")
+			if code == "" {
+				continue
+			}
+			lines := strings.Split(code, "\n")
+			for i, l := range lines {
+				lines[i] = strings.TrimRight(indent+"    "+l, " ")
+			}
+			*blocks = append(*blocks, strings.Join(lines, "\n"))
+		case *ast.HTMLBlock, *ast.ThematicBreak:
+		default:
+			collectTextPageBlocks(c, src, indent, blocks)
+		}
+	}
+}
+
+// wrapText fills text to width columns, starting the first line with first
+// and the rest with rest. Existing line breaks are kept; a word longer than
+// the width gets a line of its own.
+func wrapText(text, first, rest string, width int) string {
+	var out []string
+	prefix := first
+	for _, para := range strings.Split(text, "\n") {
+		line := prefix
+		cols := textWidth(prefix)
+		empty := true
+		for _, word := range strings.Fields(para) {
+			w := textWidth(word)
+			if !empty && cols+1+w > width {
+				out = append(out, line)
+				line, cols, empty = rest, textWidth(rest), true
+			}
+			if !empty {
+				line += " "
+				cols++
+			}
+			line += word
+			cols += w
+			empty = false
+		}
+		out = append(out, line)
+		prefix = rest
+	}
+	return strings.Join(out, "\n")
+}
+
+func textWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.In(r, unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func writeTextPage(cfg config, p post) error {
+	target := filepath.Join(cfg.outputDir, filepath.FromSlash(p.Slug), "index.txt")
+	if err := cfg.out.writeText(target, []byte(renderTextPage(cfg, p))); err != nil {
+		return fmt.Errorf("write text page %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadTimezone resolves the -timezone flag, an IANA zone name such as
+// Asia/Seoul. Zone-less front matter dates are read in it, and page dates,
+// archives, feeds and future-post checks all use it, so an unknown name
+// fails the build instead of quietly falling back to UTC.
+func loadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// inZone returns t in loc. A nil loc or a zero t is returned unchanged, so
+// undated values stay recognizable.
+func inZone(t time.Time, loc *time.Location) time.Time {
+	if loc == nil || t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
+
+// explicitZonePattern matches a timestamp that ends in a time with an
+// explicit zone: Z or a numeric offset. Date-only values never match.
+var explicitZonePattern = regexp.MustCompile(`[Tt ]\d{1,2}:\d{2}(:\d{2}(\.\d+)?)?\s*([Zz]|[+-]\d{1,2}(:?\d{2})?)$`)
+
+// zonelessDates reports which top-level date keys of the front matter meta
+// were written without a zone. YAML reads those as UTC, which -timezone
+// then corrects.
+func zonelessDates(meta []byte) map[string]bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(meta, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	out := make(map[string]bool)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		switch key, val := m.Content[i].Value, m.Content[i+1]; key {
+		case "date", "expiryDate", "lastmod":
+			if val.Kind == yaml.ScalarNode && !explicitZonePattern.MatchString(val.Value) {
+				out[key] = true
+			}
+		}
+	}
+	return out
+}
+
+// applyTimezone moves the front matter dates into loc: zone-less values
+// keep their wall clock and are read as loc time, the others keep their
+// instant. Dates, archives and feeds then all agree on the calendar day.
+func applyTimezone(fm *frontMatter, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for key, t := range map[string]*time.Time{"date": &fm.Date, "expiryDate": &fm.ExpiryDate, "lastmod": &fm.LastMod} {
+		switch {
+		case t.IsZero():
+		case fm.zoneless[key]:
+			*t = wallClockIn(*t, loc)
+		default:
+			*t = t.In(loc)
+		}
+	}
+}
+
+// wallClockIn reads t's wall clock as loc time. A wall clock skipped by a
+// spring-forward jump is read with the offset in effect before the jump, so
+// 02:30 on that night becomes 03:30; time.Date leaves the choice open.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	lt := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	if lt.Day() == t.Day() && lt.Hour() == t.Hour() && lt.Minute() == t.Minute() {
+		return lt
+	}
+	_, before := time.Date(t.Year(), t.Month(), t.Day()-1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).Zone()
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestFormatRFC1123AcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		utc  string
+		want string
+	}{
+		{"before spring forward", "2024-03-10T06:59:00Z", "Sun, 10 Mar 2024 01:59:00 -0500"},
+		{"after spring forward", "2024-03-10T07:00:00Z", "Sun, 10 Mar 2024 03:00:00 -0400"},
+		{"first 01:30 on fall back", "2024-11-03T05:30:00Z", "Sun, 03 Nov 2024 01:30:00 -0400"},
+		{"second 01:30 on fall back", "2024-11-03T06:30:00Z", "Sun, 03 Nov 2024 01:30:00 -0500"},
+		{"previous day in the zone", "2024-11-03T03:00:00Z", "Sat, 02 Nov 2024 23:00:00 -0400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := time.Parse(time.RFC3339, tt.utc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := formatRFC1123(in, ny)
+			if got != tt.want {
+				t.Errorf("formatRFC1123(%s) = %q, want %q", tt.utc, got, tt.want)
+			}
+			back, err := time.Parse(time.RFC1123Z, got)
+			if err != nil || !back.Equal(in) {
+				t.Errorf("%q does not parse back to %s (err %v)", got, tt.utc, err)
+			}
+		})
+	}
+}
+
+func TestApplyTimezoneAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		date string
+		want string
+	}{
+		{"zone-less time skipped by spring forward", "2024-03-10 02:30:00", "2024-03-10T03:30:00-04:00"},
+		{"zone-less time after fall back", "2024-11-03 12:00:00", "2024-11-03T12:00:00-05:00"},
+		{"explicit zone keeps its instant", "2024-11-03T06:30:00Z", "2024-11-03T01:30:00-05:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, _, err := splitFrontMatter([]byte("---\ntitle: DST\ndate: " + tt.date + "\n---\nbody\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			applyTimezone(&fm, ny)
+			if got := fm.Date.Format(time.RFC3339); got != tt.want {
+				t.Errorf("date = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDateInZone(t *testing.T) {
+	seoul, err := loadTimezone("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	late := time.Date(2024, 5, 3, 20, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"UTC evening is the next day in Seoul", late, seoul, "2024-05-04"},
+		{"nil location keeps the value's zone", late, nil, "2024-05-03"},
+		{"zero time stays recognizable", time.Time{}, seoul, "0001-01-01"},
+	}
+	for _, tt := range tests {
+		if got := formatDate(tt.t, tt.loc); got != tt.want {
+			t.Errorf("%s: formatDate = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTimezoneRejectsUnknownZones(t *testing.T) {
+	if _, err := loadTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Error("loadTimezone accepted an unknown zone")
+	}
+}
+
+func TestPageDatesUseTimezone(t *testing.T) {
+	cfg := testConfig(t)
+	loc, err := loadTimezone("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.location = loc
+	writeContent(t, cfg, "late.md", "---\ntitle: Late\ndate: 2024-05-03T20:30:00Z\n---\nbody\n")
+	buildSite(t, cfg)
+	if page := readOutput(t, cfg, "late/index.html"); !strings.Contains(page, "2024-05-04") {
+		t.Errorf("post page does not show the Seoul date 2024-05-04")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.outputDir, "2024", "05", "index.html")); err != nil {
+		t.Errorf("month archive: %v", err)
+	}
+}
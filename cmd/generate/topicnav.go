@@ -0,0 +1,54 @@
+package main
+
+// topicNav is the neighbourhood of a post within one tag or series. Prev is
+// the earlier post in reading order (older within a tag, the previous part
+// within a series) and Next the later one; either is nil at the ends.
+type topicNav struct {
+	Name string
+	URL  string
+	Prev *post
+	Next *post
+}
+
+// postNav holds a post's topic navigation, exposed to the post template as
+// .TagNav and .SeriesNav.
+type postNav struct {
+	Tags   []topicNav
+	Series *topicNav
+}
+
+// buildTopicNav computes prev/next neighbours for every post in every tag
+// and series, keyed by post slug. tags list posts newest first and series
+// in reading order, as buildTagGroups and buildSeries return them.
+func buildTopicNav(tags []tagGroup, series []seriesGroup) map[string]postNav {
+	navs := make(map[string]postNav)
+	for _, t := range tags {
+		for i, p := range t.Posts {
+			nav := topicNav{Name: t.Name, URL: tagPath(t.Slug)}
+			if i+1 < len(t.Posts) {
+				nav.Prev = &t.Posts[i+1]
+			}
+			if i > 0 {
+				nav.Next = &t.Posts[i-1]
+			}
+			n := navs[p.Slug]
+			n.Tags = append(n.Tags, nav)
+			navs[p.Slug] = n
+		}
+	}
+	for _, s := range series {
+		for i, p := range s.Posts {
+			nav := topicNav{Name: s.Name, URL: "/series/"}
+			if i > 0 {
+				nav.Prev = &s.Posts[i-1]
+			}
+			if i+1 < len(s.Posts) {
+				nav.Next = &s.Posts[i+1]
+			}
+			n := navs[p.Slug]
+			n.Series = &nav
+			navs[p.Slug] = n
+		}
+	}
+	return navs
+}
@@ -0,0 +1,59 @@
+package main
+
+import "strings"
+
+// Revised Romanization of Korean tables, indexed by the jamo positions used
+// in precomposed Hangul syllables (U+AC00–U+D7A3).
+var (
+	hangulInitials = []string{
+		"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s",
+		"ss", "", "j", "jj", "ch", "k", "t", "p", "h",
+	}
+	hangulVowels = []string{
+		"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa",
+		"wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i",
+	}
+	hangulFinals = []string{
+		"", "k", "k", "k", "n", "n", "n", "t", "l", "k",
+		"m", "l", "l", "l", "p", "l", "m", "p", "p", "t",
+		"t", "ng", "t", "t", "k", "t", "p", "t",
+	}
+)
+
+const (
+	hangulBase     = 0xAC00
+	hangulLast     = 0xD7A3
+	hangulFinalL   = 8
+	hangulInitialR = 5
+)
+
+func isHangulSyllable(r rune) bool {
+	return r >= hangulBase && r <= hangulLast
+}
+
+// romanizeHangul transliterates a run of Hangul syllables to ASCII using the
+// Revised Romanization syllable tables. Only the ㄹㄹ → "ll" assimilation is
+// applied; everything else is romanized syllable by syllable, which keeps
+// the output predictable for use in URLs.
+func romanizeHangul(s string) string {
+	var b strings.Builder
+	prevFinal := -1
+	for _, r := range s {
+		if !isHangulSyllable(r) {
+			b.WriteRune(r)
+			prevFinal = -1
+			continue
+		}
+		idx := int(r - hangulBase)
+		initial, vowel, final := idx/588, (idx%588)/28, idx%28
+		if initial == hangulInitialR && prevFinal == hangulFinalL {
+			b.WriteString("l")
+		} else {
+			b.WriteString(hangulInitials[initial])
+		}
+		b.WriteString(hangulVowels[vowel])
+		b.WriteString(hangulFinals[final])
+		prevFinal = final
+	}
+	return b.String()
+}
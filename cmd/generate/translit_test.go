@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestRomanizeHangul(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"서울":       "seoul",
+		"갑":        "gap",
+		"값":        "gap",
+		"꽃":        "kkot",
+		"읽기":       "ikgi",
+		"한국어":      "hangukeo",
+		"실력":       "sillyeok",
+		"신라":       "sinra",
+		"Go 언어":    "Go eoneo",
+		"k8s 클러스터": "k8s keulleoseuteo",
+	}
+	for in, want := range tests {
+		if got := romanizeHangul(in); got != want {
+			t.Errorf("romanizeHangul(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
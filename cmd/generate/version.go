@@ -0,0 +1,45 @@
+package main
+
+import "runtime/debug"
+
+// version is set at build time with
+// -ldflags "-X main.version=v1.2.3". When it is left empty the module
+// version or VCS revision recorded by the Go toolchain is used instead.
+var version string
+
+const generatorName = "pebbleblog"
+
+// generatorVersion returns the version stamped into output, e.g. "v1.2.3",
+// "abc1234def56" or "(devel)".
+func generatorVersion() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	var revision string
+	dirty := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "(devel)"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if dirty {
+		revision += "-dirty"
+	}
+	return revision
+}